@@ -0,0 +1,166 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/exit"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// archives of sessions are refs on the remote, 'mob reset --archive' creates refs/mob-archive/<wip-branch>/<timestamp>
+// and 'mob clean --remote --archive tag' creates the tags mob-archive/<wip-branch>/<timestamp>
+const (
+	archiveRefPrefix = "refs/mob-archive/"
+	archiveTagPrefix = "refs/tags/mob-archive/"
+)
+
+// suffix of the archive of the local wip branch, if it differs from the remote wip branch
+const localArchiveSuffix = "-local"
+
+type sessionArchive struct {
+	id     string // <wip-branch>/<timestamp>
+	ref    string
+	commit string
+}
+
+func archive(configuration config.Configuration, parameter []string) {
+	if len(parameter) == 0 || parameter[0] == "list" {
+		listArchives(configuration)
+		return
+	}
+	if parameter[0] == "restore" && len(parameter) == 2 {
+		restoreArchive(configuration, parameter[1])
+		return
+	}
+	say.Error("Unknown archive command '" + strings.Join(parameter, " ") + "'.")
+	say.Fix("To show the archived sessions, use", configuration.Mob("archive list"))
+	exit.Exit(1)
+}
+
+// pushes the tips of the remote and the local wip branch as archive refs, returns false if that failed
+func archiveWipBranch(configuration config.Configuration, wipBranch Branch) bool {
+	ref := archiveRefPrefix + wipBranch.Name + "/" + time.Now().UTC().Format("20060102-150405")
+	remoteCommit := ""
+	if wipBranch.hasRemoteBranch(configuration) {
+		remoteCommit = silentgit("rev-parse", "refs/remotes/"+wipBranch.remote(configuration).Name)
+		if !pushArchive(configuration, remoteCommit, ref) {
+			return false
+		}
+	}
+	if wipBranch.hasLocalBranch() {
+		if localCommit := silentgit("rev-parse", "refs/heads/"+wipBranch.Name); localCommit != remoteCommit {
+			if remoteCommit != "" {
+				ref += localArchiveSuffix
+			}
+			if !pushArchive(configuration, localCommit, ref) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func pushArchive(configuration config.Configuration, commit string, ref string) bool {
+	if _, err := silentgitignorefailure("push", configuration.RemoteName, commit+":"+ref); err != nil {
+		say.Warning("Could not push " + ref + ": " + err.Error())
+		return false
+	}
+	say.Info("Archived " + commit[:min(len(commit), 7)] + " as " + strings.TrimPrefix(ref, archiveRefPrefix))
+	return true
+}
+
+func listArchives(configuration config.Configuration) {
+	archives, err := getArchives(configuration)
+	if err != nil {
+		say.Error("Could not list the archived sessions on " + configuration.RemoteName + ": " + err.Error())
+		exit.Exit(1)
+		return
+	}
+	if len(archives) == 0 {
+		say.Info("there are no archived sessions on " + configuration.RemoteName)
+		return
+	}
+	say.Info("archived sessions on " + configuration.RemoteName + ":")
+	for _, archive := range archives {
+		say.InfoIndented(archive.id + " (" + archive.commit[:min(len(archive.commit), 7)] + ")")
+	}
+	say.Fix("To restore a session, use", configuration.Mob("archive restore <id>"))
+}
+
+// the archives on the remote, the most recent first
+func getArchives(configuration config.Configuration) ([]sessionArchive, error) {
+	output, err := silentgitignorefailure("ls-remote", configuration.RemoteName, archiveRefPrefix+"*", archiveTagPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	archives := []sessionArchive{}
+	for _, line := range strings.Split(output, "\n") {
+		commit, ref, found := strings.Cut(line, "\t")
+		if !found || strings.HasSuffix(ref, "^{}") {
+			continue
+		}
+		id := strings.TrimPrefix(strings.TrimPrefix(ref, archiveRefPrefix), archiveTagPrefix)
+		archives = append(archives, sessionArchive{id: id, ref: ref, commit: commit})
+	}
+	sort.SliceStable(archives, func(i, j int) bool {
+		return archiveTimestamp(archives[i].id) > archiveTimestamp(archives[j].id)
+	})
+	return archives, nil
+}
+
+func archiveTimestamp(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
+
+// the wip branch of the archive, e.g. mob/main-green for mob/main-green/20240102-150405
+func (a sessionArchive) wipBranch() Branch {
+	return newBranch(a.id[:max(strings.LastIndex(a.id, "/"), 0)])
+}
+
+func restoreArchive(configuration config.Configuration, id string) {
+	archives, err := getArchives(configuration)
+	if err != nil {
+		say.Error("Could not list the archived sessions on " + configuration.RemoteName + ": " + err.Error())
+		exit.Exit(1)
+		return
+	}
+	var found *sessionArchive
+	for i := range archives {
+		if archives[i].id == id {
+			found = &archives[i]
+			break
+		}
+	}
+	if found == nil {
+		say.Error("There is no archived session '" + id + "'.")
+		say.Fix("To show the archived sessions, use", configuration.Mob("archive list"))
+		exit.Exit(1)
+		return
+	}
+
+	wipBranch := found.wipBranch()
+	git("fetch", configuration.RemoteName, "--prune")
+	if wipBranch.hasRemoteBranch(configuration) {
+		say.Error("Cannot restore " + id + ", because " + wipBranch.remote(configuration).Name + " exists.")
+		say.Fix("To remove the existing session first, use", configuration.Mob("reset --delete-remote-wip-branch --archive"))
+		exit.Exit(1)
+		return
+	}
+	git("fetch", configuration.RemoteName, found.ref)
+	gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "FETCH_HEAD:refs/heads/"+wipBranch.Name)
+	git("fetch", configuration.RemoteName)
+	say.Info("restored " + id + " as " + wipBranch.remote(configuration).Name)
+
+	baseBranch, qualifier := splitWipBranch(wipBranch, remoteBaseBranches(gitRemoteBranches(), configuration), configuration)
+	if baseBranch == "" {
+		return
+	}
+	startCommand := "start"
+	if qualifier != "" {
+		startCommand += " --branch " + qualifier
+	}
+	say.Fix("To join the restored session, go to '"+baseBranch+"' and use", configuration.Mob(startCommand))
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResetArchivesWipBranch(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	wipCommit := silentgit("rev-parse", "origin/mob/master-green")
+	configuration.WipBranchQualifier = "green"
+	configuration.ResetDeleteRemoteWipBranch = true
+	configuration.ResetArchive = true
+
+	reset(configuration)
+
+	assertOutputContains(t, output, "Archived "+wipCommit[:7]+" as mob/master-green/")
+	archives, _ := getArchives(configuration)
+	equals(t, 1, len(archives))
+	equals(t, wipCommit, archives[0].commit)
+	equals(t, "mob/master-green", archives[0].wipBranch().Name)
+	equals(t, false, newBranch("mob/master-green").hasRemoteBranch(configuration))
+}
+
+func TestResetDoesNotArchiveByDefault(t *testing.T) {
+	_, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	configuration.WipBranchQualifier = "green"
+	configuration.ResetDeleteRemoteWipBranch = true
+
+	reset(configuration)
+
+	archives, _ := getArchives(configuration)
+	equals(t, 0, len(archives))
+}
+
+func TestResetArchivesUnpushedLocalCommits(t *testing.T) {
+	_, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	git("checkout", "mob/master-green")
+	createFileAndCommitIt(t, "unpushed.txt", "contentIrrelevant", "unpushed commit")
+	localCommit := silentgit("rev-parse", "HEAD")
+	configuration.WipBranchQualifier = "green"
+	configuration.ResetDeleteRemoteWipBranch = true
+	configuration.ResetArchive = true
+
+	reset(configuration)
+
+	archives, _ := getArchives(configuration)
+	equals(t, 2, len(archives))
+	local := archives[0]
+	if strings.HasSuffix(archives[1].id, localArchiveSuffix) {
+		local = archives[1]
+	}
+	equals(t, true, strings.HasSuffix(local.id, localArchiveSuffix))
+	equals(t, localCommit, local.commit)
+}
+
+func TestArchiveList(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	configuration.WipBranchQualifier = "green"
+	configuration.ResetDeleteRemoteWipBranch = true
+	configuration.ResetArchive = true
+	reset(configuration)
+
+	archive(configuration, []string{"list"})
+
+	assertOutputContains(t, output, "archived sessions on origin:")
+	assertOutputContains(t, output, "  mob/master-green/")
+	assertOutputContains(t, output, "mob archive restore <id>")
+}
+
+func TestArchiveListWithoutArchives(t *testing.T) {
+	output, configuration := setup(t)
+
+	archive(configuration, []string{})
+
+	assertOutputContains(t, output, "there are no archived sessions on origin")
+}
+
+func TestArchiveListShowsTagsOfClean(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	cleanRemote(configuration, []string{"--remote", "--older-than", "0m", "--archive", "tag", "--yes"})
+
+	archive(configuration, []string{"list"})
+
+	assertOutputContains(t, output, "  mob/master-green/")
+}
+
+func TestArchiveRestore(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	wipCommit := silentgit("rev-parse", "origin/mob/master-green")
+	configuration.WipBranchQualifier = "green"
+	configuration.ResetDeleteRemoteWipBranch = true
+	configuration.ResetArchive = true
+	reset(configuration)
+	archives, _ := getArchives(configuration)
+
+	archive(configuration, []string{"restore", archives[0].id})
+
+	assertOutputContains(t, output, "restored "+archives[0].id+" as origin/mob/master-green")
+	assertOutputContains(t, output, "To join the restored session, go to 'master' and use")
+	assertOutputContains(t, output, "mob start --branch green")
+	equals(t, wipCommit, silentgit("rev-parse", "origin/mob/master-green"))
+}
+
+func TestArchiveRestoreFailsWhenWipBranchExists(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	configuration.WipBranchQualifier = "green"
+	archiveWipBranch(configuration, newBranch("mob/master-green"))
+	archives, _ := getArchives(configuration)
+	mockExit()
+	defer resetExit()
+
+	archive(configuration, []string{"restore", archives[0].id})
+
+	assertOutputContains(t, output, "Cannot restore "+archives[0].id+", because origin/mob/master-green exists.")
+}
+
+func TestArchiveRestoreFailsForUnknownId(t *testing.T) {
+	output, configuration := setup(t)
+	mockExit()
+	defer resetExit()
+
+	archive(configuration, []string{"restore", "mob/master-green/20240101-000000"})
+
+	assertOutputContains(t, output, "There is no archived session 'mob/master-green/20240101-000000'.")
+}
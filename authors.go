@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/remotemobprogramming/mob/v5/coauthors"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/exit"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// the people declared with 'mob with', one author per line
+const sessionParticipantsFile = "mob-with"
+
+func with(configuration config.Configuration, parameter []string) {
+	if len(parameter) == 0 {
+		sayParticipants(configuration)
+		return
+	}
+	if parameter[0] == "--clear" {
+		clearParticipants()
+		say.Info("cleared the people you are mobbing with")
+		return
+	}
+
+	registry := readAuthorRegistry()
+	var participants []coauthors.Author
+	for _, alias := range parameter {
+		if author, found := registry.Lookup(alias); found {
+			participants = append(participants, author)
+		} else if coauthors.IsAuthor(alias) {
+			participants = append(participants, strings.TrimSpace(alias))
+		} else {
+			say.Error("Unknown author '" + alias + "'.")
+			say.Fix("To fix, add the alias to "+coauthors.RegistryFileName+" or use", configuration.Mob("with \""+alias+" <email>\""))
+			exit.Exit(1)
+			return
+		}
+	}
+	if err := os.WriteFile(gitDir()+"/"+sessionParticipantsFile, []byte(strings.Join(participants, "\n")+"\n"), 0644); err != nil {
+		say.Error("Could not save the people you are mobbing with: " + err.Error())
+		exit.Exit(1)
+		return
+	}
+	sayParticipants(configuration)
+}
+
+func sayParticipants(configuration config.Configuration) {
+	participants := readParticipants()
+	if len(participants) == 0 {
+		say.Info("you did not declare who you are mobbing with")
+		say.Fix("To add everyone in the session as co-authors, use", configuration.Mob("with <alias> <alias>..."))
+		return
+	}
+	say.Info("mobbing with:")
+	for _, participant := range participants {
+		say.InfoIndented(participant)
+	}
+}
+
+func readParticipants() []coauthors.Author {
+	content, err := os.ReadFile(gitDir() + "/" + sessionParticipantsFile)
+	if err != nil {
+		return []coauthors.Author{}
+	}
+	var participants []coauthors.Author
+	for _, line := range strings.Split(string(content), "\n") {
+		if coauthors.IsAuthor(line) {
+			participants = append(participants, strings.TrimSpace(line))
+		}
+	}
+	return participants
+}
+
+// the session ends with 'mob done' or 'mob reset'
+func clearParticipants() {
+	if err := os.Remove(gitDir() + "/" + sessionParticipantsFile); err != nil && !os.IsNotExist(err) {
+		say.Warning(err.Error())
+	}
+}
+
+func readAuthorRegistry() coauthors.Registry {
+	registry, err := coauthors.ReadRegistry(gitRootDir() + "/" + coauthors.RegistryFileName)
+	if err != nil {
+		say.Warning("Could not read " + coauthors.RegistryFileName + ": " + err.Error())
+	}
+	return registry
+}
+
+func currentAuthor() coauthors.Author {
+	return gitUserName() + " <" + gitUserEmail() + ">"
+}
+
+// declared participants other than the current user, added as trailers to every wip commit
+func participantsAsCoauthors() []coauthors.Author {
+	participants := readParticipants()
+	if len(participants) == 0 {
+		return participants
+	}
+	return coauthors.Normalise(readAuthorRegistry(), currentAuthor(), participants)
+}
+
+// authors and co-authors of the commits without the current user
+func collectCoauthors(revisions ...string) []coauthors.Author {
+	return coauthors.Collect(gitClient, readAuthorRegistry(), currentAuthor(), revisions...)
+}
+
+// everyone who contributed to the wip branch, local or remote, but not to the base branch, and everyone declared with
+// 'mob with'
+func collectSessionCoauthors(configuration config.Configuration, baseBranch Branch, wipBranch Branch) []coauthors.Author {
+	revisions := []string{"^" + baseBranch.remote(configuration).Name, wipBranch.Name}
+	if wipBranch.hasRemoteBranch(configuration) {
+		revisions = append(revisions, wipBranch.remote(configuration).Name)
+	}
+	return coauthors.Normalise(readAuthorRegistry(), currentAuthor(), append(collectCoauthors(revisions...), readParticipants()...))
+}
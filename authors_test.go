@@ -0,0 +1,111 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWithAddsParticipantsAsCoAuthors(t *testing.T) {
+	output, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, ".mob-authors", "cd = Carol Diaz <carol@example.com>\n")
+	with(configuration, []string{"cd", "Dave <dave@example.com>"})
+	next(configuration)
+
+	assertOutputContains(t, output, "Carol Diaz <carol@example.com>")
+	equals(t, configuration.WipCommitMessage+"\n\nlastFile:.mob-authors\nMob-Last-File-Line: 1\nMob-Driver: local <local@example.com>\nMob-Rotation: 1\nCo-authored-by: Dave <dave@example.com>\nCo-authored-by: Carol Diaz <carol@example.com>", silentgit("log", "-1", "--pretty=format:%B", "origin/mob-session"))
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	done(configuration)
+
+	squashMsg := readFile(t, filepath.Join(tempDir, "alice", ".git", "SQUASH_MSG"))
+	assertOutputContains(t, &squashMsg, "Co-authored-by: Carol Diaz <carol@example.com>\n")
+	assertOutputContains(t, &squashMsg, "Co-authored-by: local <local@example.com>\n")
+}
+
+func TestWithDeclaredParticipantsBecomeCoAuthorsWithoutDriving(t *testing.T) {
+	_, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	with(configuration, []string{"Dave <dave@example.com>"})
+	createFile(t, "file1.txt", "contentIrrelevant")
+	done(configuration)
+
+	squashMsg := readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG"))
+	assertOutputContains(t, &squashMsg, "\nCo-authored-by: Dave <dave@example.com>\n")
+	equals(t, []string{}, readParticipants())
+}
+
+func TestWithUnknownAlias(t *testing.T) {
+	output, configuration := setup(t)
+	mockExit()
+	defer resetExit()
+
+	setWorkingDir(tempDir + "/local")
+	with(configuration, []string{"xy"})
+
+	assertOutputContains(t, output, "Unknown author 'xy'.")
+	equals(t, []string{}, readParticipants())
+}
+
+func TestWithClear(t *testing.T) {
+	output, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/local")
+	with(configuration, []string{"Dave <dave@example.com>"})
+	with(configuration, []string{"--clear"})
+	with(configuration, []string{})
+
+	equals(t, []string{}, readParticipants())
+	assertOutputContains(t, output, "you did not declare who you are mobbing with")
+}
+
+func TestDoneResolvesCoAuthorsWithRegistry(t *testing.T) {
+	_, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, ".mob-authors", "alice-laptop = Alice Bauer <alice@example.com>\n")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	git("config", "--local", "user.name", "alice-laptop")
+	git("config", "--local", "user.email", "alice@laptop.local")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	done(configuration)
+
+	squashMsg := readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG"))
+	assertOutputContains(t, &squashMsg, "\nCo-authored-by: Alice Bauer <alice@example.com>\n")
+	assertOutputNotContains(t, &squashMsg, "Co-authored-by: alice")
+}
+
+func TestShowNextResolvesCommittersWithRegistry(t *testing.T) {
+	output, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, ".mob-authors", "alice-laptop = alice <alice@example.com>\n")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	git("config", "--local", "user.name", "alice-laptop")
+	start(configuration)
+	createFile(t, "file3.txt", "contentIrrelevant")
+	next(configuration)
+
+	assertOutputContains(t, output, "***local*** is (probably) next.")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// 'mob start' stores the base branch of the wip branch in the git config as branch.<wip-branch>.mobBaseBranch, because
+// the name alone is ambiguous, e.g. 'mob/feature-x' is the wip branch of 'feature-x' and of 'feature' with qualifier 'x'
+const baseBranchConfigKey = "mobBaseBranch"
+
+// shares the base branch with everyone who joins the session: the ref points to a blob with the name of the base branch,
+// it is pushed and deleted together with the wip branch and fetched with every 'git fetch' of the remote
+const baseBranchRefPrefix = "refs/mob/base/"
+
+func baseBranchRef(wipBranch Branch) string {
+	return baseBranchRefPrefix + wipBranch.Name
+}
+
+func rememberBaseBranch(wipBranch Branch, baseBranch Branch) {
+	if _, err := silentgitignorefailure("config", "branch."+wipBranch.Name+"."+baseBranchConfigKey, baseBranch.Name); err != nil {
+		say.Debug("could not remember base branch of " + wipBranch.Name + ": " + err.Error())
+	}
+}
+
+// adds the shared base branches to the refs fetched from the remote, so that fetching them costs no extra round trip
+func fetchBaseBranchesWithRemote(configuration config.Configuration) {
+	key := "remote." + configuration.RemoteName + ".fetch"
+	refspec := "+" + baseBranchRefPrefix + "*:" + baseBranchRefPrefix + "*"
+	if fetchRefspecs, _ := silentgitignorefailure("config", "--get-all", key); stringContains(strings.Split(fetchRefspecs, "\n"), refspec) {
+		return
+	}
+	if _, err := silentgitignorefailure("config", "--add", key, refspec); err != nil {
+		say.Debug("could not fetch the shared base branches with " + configuration.RemoteName + ": " + err.Error())
+	}
+}
+
+// the refspec to push the base branch of the new wip branch with it, empty if it cannot be shared
+func shareBaseBranchRefspec(wipBranch Branch, baseBranch Branch) string {
+	file := gitDir() + "/MOB_BASE_BRANCH"
+	defer os.Remove(file)
+	if err := os.WriteFile(file, []byte(baseBranch.Name+"\n"), 0644); err != nil {
+		say.Debug("could not share base branch of " + wipBranch.Name + ": " + err.Error())
+		return ""
+	}
+	blob, err := silentgitignorefailure("hash-object", "-w", file)
+	if err == nil {
+		_, err = silentgitignorefailure("update-ref", baseBranchRef(wipBranch), blob)
+	}
+	if err != nil {
+		say.Debug("could not share base branch of " + wipBranch.Name + ": " + err.Error())
+		return ""
+	}
+	return "+" + baseBranchRef(wipBranch) + ":" + baseBranchRef(wipBranch)
+}
+
+// the shared base branch to delete with the wip branch, empty if the remote had none at the last fetch
+func sharedBaseBranchRefToDelete(wipBranch Branch) string {
+	if _, found := sharedBaseBranchOf(wipBranch); !found {
+		return ""
+	}
+	return baseBranchRef(wipBranch)
+}
+
+func forgetBaseBranch(wipBranch Branch) {
+	if _, err := silentgitignorefailure("update-ref", "-d", baseBranchRef(wipBranch)); err != nil {
+		say.Debug("no shared base branch to forget: " + err.Error())
+	}
+}
+
+// the base branch of the wip branch stored by 'mob start' on this machine, else the one shared by whoever started it
+func rememberedBaseBranch(wipBranch Branch) (Branch, bool) {
+	output, err := silentgitignorefailure("config", "--get", "branch."+wipBranch.Name+"."+baseBranchConfigKey)
+	if err != nil || output == "" {
+		return sharedBaseBranchOf(wipBranch)
+	}
+	return newBranch(output), true
+}
+
+func sharedBaseBranchOf(wipBranch Branch) (Branch, bool) {
+	output, err := silentgitignorefailure("cat-file", "blob", baseBranchRef(wipBranch))
+	if err != nil || output == "" {
+		return Branch{}, false
+	}
+	return newBranch(output), true
+}
+
+// the base branch of the wip branch: the remembered one, else the one of the configured qualifier, else the longest
+// existing branch the name starts with, else the name without anything after the separator (for existing wip branches)
+func baseBranchOf(wipBranch Branch, localBranches []string, configuration config.Configuration) Branch {
+	if baseBranch, found := rememberedBaseBranch(wipBranch); found && wipBranchQualifierOf(wipBranch, baseBranch, configuration) != nil {
+		return baseBranch
+	}
+	name := wipBranch.removeWipPrefix(configuration)
+	if configuration.CustomWipBranchQualifierConfigured() && strings.HasSuffix(name.Name, configuration.WipBranchQualifierSuffix()) {
+		return name.removeWipQualifierSuffix(configuration)
+	}
+	if baseBranch, _ := splitWipBranch(wipBranch, localBranches, configuration); baseBranch != "" {
+		return newBranch(baseBranch)
+	}
+	return name.removeWipQualifier(localBranches, configuration)
+}
+
+// the qualifier of the wip branch of the base branch, nil if the wip branch does not belong to the base branch
+func wipBranchQualifierOf(wipBranch Branch, baseBranch Branch, configuration config.Configuration) *string {
+	name := wipBranch.removeWipPrefix(configuration).Name
+	qualifier := ""
+	if name == baseBranch.Name {
+		return &qualifier
+	}
+	if !strings.HasPrefix(name, baseBranch.Name+configuration.WipBranchQualifierSeparator) {
+		return nil
+	}
+	qualifier = strings.TrimPrefix(name, baseBranch.Name+configuration.WipBranchQualifierSeparator)
+	return &qualifier
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestDetermineBranchesWithSeparatorInBaseBranch(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+
+	baseBranch, _ := determineBranches(newBranch("mob/feature-x-green"), []string{"feature", "feature-x"}, configuration)
+	equals(t, "feature-x", baseBranch.Name)
+
+	baseBranch, _ = determineBranches(newBranch("mob/feature-x"), []string{"feature", "feature-x"}, configuration)
+	equals(t, "feature-x", baseBranch.Name)
+}
+
+func TestDetermineBranchesPrefersConfiguredQualifier(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+	configuration.WipBranchQualifier = "x-green"
+
+	baseBranch, wipBranch := determineBranches(newBranch("mob/feature-x-green"), []string{"feature", "feature-x"}, configuration)
+
+	equals(t, "feature", baseBranch.Name)
+	equals(t, "mob/feature-x-green", wipBranch.Name)
+}
+
+func TestStartRemembersBaseBranch(t *testing.T) {
+	_, configuration := setup(t)
+	git("checkout", "-b", "feature")
+	git("push", "origin", "feature", "--set-upstream")
+	git("checkout", "-b", "feature-x")
+	git("push", "origin", "feature-x", "--set-upstream")
+	git("checkout", "feature")
+	configuration.WipBranchQualifier = "x"
+
+	start(configuration)
+
+	assertOnBranch(t, "mob/feature-x")
+	baseBranch, _ := determineBranches(gitCurrentBranch(), gitBranches(), config.GetDefaultConfiguration())
+	equals(t, "feature", baseBranch.Name)
+	equals(t, "x", enrichConfigurationWithBranchQualifier(config.GetDefaultConfiguration()).WipBranchQualifier)
+}
+
+func TestStartSharesBaseBranchWithOtherClones(t *testing.T) {
+	_, configuration := setup(t)
+	git("checkout", "-b", "feature")
+	git("push", "origin", "feature", "--set-upstream")
+	git("checkout", "-b", "feature-x")
+	git("push", "origin", "feature-x", "--set-upstream")
+	git("checkout", "feature")
+	configuration.WipBranchQualifier = "x"
+	start(configuration)
+
+	setWorkingDir(tempDir + "/alice")
+	git("fetch", "origin")
+	git("checkout", "feature")
+	git("checkout", "feature-x")
+	git("checkout", "mob/feature-x")
+	start(config.GetDefaultConfiguration())
+
+	assertOnBranch(t, "mob/feature-x")
+	baseBranch, _ := determineBranches(gitCurrentBranch(), gitBranches(), config.GetDefaultConfiguration())
+	equals(t, "feature", baseBranch.Name)
+}
+
+func TestStartPushesSharedBaseBranchWithWipBranch(t *testing.T) {
+	output, configuration := setup(t)
+
+	start(configuration)
+
+	assertOutputContains(t, output, "git push --no-verify --set-upstream origin mob-session:mob-session +refs/mob/base/mob-session:refs/mob/base/mob-session")
+	equals(t, "master", silentgit("--git-dir", tempDir+"/remote", "cat-file", "blob", baseBranchRef(newBranch("mob-session"))))
+}
+
+func TestDoneDeletesSharedBaseBranchWithWipBranch(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	equals(t, "master", silentgit("cat-file", "blob", baseBranchRef(newBranch("mob-session"))))
+
+	done(configuration)
+
+	assertOutputContains(t, output, "git push --no-verify origin --delete mob-session refs/mob/base/mob-session")
+	equals(t, "", silentgit("ls-remote", "origin", baseBranchRef(newBranch("mob-session"))))
+	_, found := sharedBaseBranchOf(newBranch("mob-session"))
+	equals(t, false, found)
+}
+
+func TestRememberedBaseBranchIsIgnoredIfWipBranchDoesNotBelongToIt(t *testing.T) {
+	_, configuration := setup(t)
+	git("checkout", "-b", "feature1")
+	git("push", "origin", "feature1", "--set-upstream")
+	git("checkout", "-b", "mob/feature1-green")
+	rememberBaseBranch(newBranch("mob/feature1-green"), newBranch("master"))
+
+	baseBranch, _ := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+
+	equals(t, "feature1", baseBranch.Name)
+}
+
+func TestWipBranchQualifierOf(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+
+	equals(t, "", *wipBranchQualifierOf(newBranch("mob/feature-x"), newBranch("feature-x"), configuration))
+	equals(t, "x", *wipBranchQualifierOf(newBranch("mob/feature-x"), newBranch("feature"), configuration))
+	equals(t, "green-blue", *wipBranchQualifierOf(newBranch("mob/main-green-blue"), newBranch("main"), configuration))
+	equals(t, true, wipBranchQualifierOf(newBranch("mob/featurex"), newBranch("feature"), configuration) == nil)
+}
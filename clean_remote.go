@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/exit"
+	"github.com/remotemobprogramming/mob/v5/input"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+const (
+	archiveAsTag    = "tag"
+	archiveAsBundle = "bundle"
+)
+
+// where 'mob clean --remote --archive bundle' writes the bundles, in the git dir
+const archiveBundleDir = "mob-archive"
+
+type cleanOptions struct {
+	remote    bool
+	olderThan string
+	archive   string
+	yes       bool
+}
+
+func parseCleanOptions(parameters []string, configuration config.Configuration) (cleanOptions, error) {
+	options := cleanOptions{olderThan: configuration.SessionStaleAfter}
+	for i := 0; i < len(parameters); i++ {
+		switch parameters[i] {
+		case "--remote":
+			options.remote = true
+		case "--yes", "-y":
+			options.yes = true
+		case "--older-than", "--archive":
+			if i+1 == len(parameters) {
+				return options, errors.New("missing value for " + parameters[i])
+			}
+			if parameters[i] == "--older-than" {
+				options.olderThan = parameters[i+1]
+			} else {
+				options.archive = parameters[i+1]
+			}
+			i++ // skip consumed parameter
+		default:
+			return options, errors.New("unknown option for clean: " + parameters[i])
+		}
+	}
+	if options.archive != "" && options.archive != archiveAsTag && options.archive != archiveAsBundle {
+		return options, errors.New("unknown archive '" + options.archive + "', use '" + archiveAsTag + "' or '" + archiveAsBundle + "'")
+	}
+	if !options.remote && len(parameters) > 0 {
+		return options, errors.New("the options of clean require --remote")
+	}
+	if _, err := config.ParseAge(options.olderThan); err != nil {
+		return options, err
+	}
+	return options, nil
+}
+
+func cleanRemote(configuration config.Configuration, parameters []string) {
+	options, err := parseCleanOptions(parameters, configuration)
+	if err != nil {
+		say.Error(err.Error())
+		exit.Exit(1)
+		return
+	}
+	if options.remote {
+		fetchBaseBranchesWithRemote(configuration)
+		git("fetch", configuration.RemoteName, "--prune")
+		cleanRemoteWipBranches(configuration, options)
+	}
+	clean(configuration)
+}
+
+// deletes the remote wip branches without commits newer than the cutoff, after the user confirmed it
+func cleanRemoteWipBranches(configuration config.Configuration, options cleanOptions) {
+	age, _ := config.ParseAge(options.olderThan)
+	cutoff := time.Now().Add(-age)
+
+	var abandoned []Session
+	for _, session := range getSessions(configuration) {
+		if session.LastActivity.Before(cutoff) {
+			abandoned = append(abandoned, session)
+		}
+	}
+	if len(abandoned) == 0 {
+		say.Info("there are no remote wip branches without commits since " + cutoff.Format("2006-01-02 15:04"))
+		return
+	}
+
+	say.Info("remote wip branches without commits since " + cutoff.Format("2006-01-02 15:04") + ":")
+	for _, session := range abandoned {
+		say.InfoIndented(session.Branch + " (last activity " + session.LastActivity.Format("2006-01-02 15:04") + ")")
+	}
+	if !options.yes {
+		if !input.IsInteractive() {
+			say.Fix("To delete them, use", configuration.Mob("clean --remote --older-than "+options.olderThan+" --yes"))
+			return
+		}
+		if !input.Confirm("Delete " + strconv.Itoa(len(abandoned)) + " remote wip branches?") {
+			say.Info("nothing was deleted")
+			return
+		}
+	}
+
+	for _, session := range abandoned {
+		wipBranch := strings.TrimPrefix(session.Branch, configuration.RemoteName+"/")
+		if !archiveRemoteWipBranch(configuration, options.archive, session, wipBranch) {
+			say.Warning("Skipped deleting " + session.Branch + ", because it could not be archived")
+			continue
+		}
+		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", wipBranch, sharedBaseBranchRefToDelete(newBranch(wipBranch)))
+		forgetBaseBranch(newBranch(wipBranch))
+		say.Info("Removed remote wip branch " + session.Branch)
+	}
+}
+
+func archiveRemoteWipBranch(configuration config.Configuration, archive string, session Session, wipBranch string) bool {
+	timestamp := session.LastActivity.UTC().Format("20060102-150405")
+	switch archive {
+	case archiveAsTag:
+		tag := strings.TrimPrefix(archiveTagPrefix, "refs/tags/") + wipBranch + "/" + timestamp
+		if _, err := silentgitignorefailure("push", configuration.RemoteName, "refs/remotes/"+session.Branch+":"+archiveTagPrefix+wipBranch+"/"+timestamp); err != nil {
+			say.Warning("Could not push tag " + tag + ": " + err.Error())
+			return false
+		}
+		say.Info("Archived " + session.Branch + " as tag " + tag)
+	case archiveAsBundle:
+		directory := gitDir() + "/" + archiveBundleDir
+		if err := os.MkdirAll(directory, 0755); err != nil {
+			say.Warning("Could not create " + directory + ": " + err.Error())
+			return false
+		}
+		bundle := directory + "/" + strings.ReplaceAll(wipBranch, "/", "_") + "-" + timestamp + ".bundle"
+		if _, err := silentgitignorefailure("bundle", "create", bundle, "refs/remotes/"+session.Branch); err != nil {
+			say.Warning("Could not create bundle " + bundle + ": " + err.Error())
+			return false
+		}
+		say.Info("Archived " + session.Branch + " in " + bundle)
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCleanRemoteAsksForYesWhenNotInteractive(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+
+	cleanRemote(configuration, []string{"--remote", "--older-than", "0m"})
+
+	assertOutputContains(t, output, "origin/mob/master-green (last activity ")
+	assertOutputContains(t, output, "mob clean --remote --older-than 0m --yes")
+	equals(t, 1, len(getSessions(configuration)))
+}
+
+func TestCleanRemoteDeletesAbandonedWipBranchesAfterConfirmation(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	mockInteractiveInput(t, "y\n")
+
+	cleanRemote(configuration, []string{"--remote", "--older-than", "0m"})
+
+	assertOutputContains(t, output, "Removed remote wip branch origin/mob/master-green")
+	equals(t, 0, len(getSessions(configuration)))
+	assertOnBranch(t, "master")
+	equals(t, false, newBranch("mob/master-green").hasLocalBranch())
+}
+
+func TestCleanRemoteKeepsWipBranchesWhenDeclined(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	mockInteractiveInput(t, "n\n")
+
+	cleanRemote(configuration, []string{"--remote", "--older-than", "0m"})
+
+	assertOutputContains(t, output, "nothing was deleted")
+	equals(t, 1, len(getSessions(configuration)))
+}
+
+func TestCleanRemoteKeepsRecentWipBranches(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+
+	cleanRemote(configuration, []string{"--remote", "--older-than", "30d", "--yes"})
+
+	assertOutputContains(t, output, "there are no remote wip branches without commits since ")
+	equals(t, 1, len(getSessions(configuration)))
+}
+
+func TestCleanRemoteArchivesAsTag(t *testing.T) {
+	_, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	wipCommit := silentgit("rev-parse", "origin/mob/master-green")
+
+	cleanRemote(configuration, []string{"--remote", "--older-than", "0m", "--archive", "tag", "--yes"})
+
+	equals(t, 0, len(getSessions(configuration)))
+	tags := silentgit("ls-remote", "--tags", "origin", "mob-archive/*")
+	assertOutputContains(t, &tags, wipCommit+"\trefs/tags/mob-archive/mob/master-green/")
+}
+
+func TestCleanRemoteArchivesAsBundle(t *testing.T) {
+	_, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+
+	cleanRemote(configuration, []string{"--remote", "--older-than", "0m", "--archive", "bundle", "--yes"})
+
+	equals(t, 0, len(getSessions(configuration)))
+	bundles, _ := filepath.Glob(filepath.Join(tempDir, "local", ".git", "mob-archive", "mob_master-green-*.bundle"))
+	equals(t, 1, len(bundles))
+	heads := silentgit("bundle", "list-heads", bundles[0])
+	assertOutputContains(t, &heads, "refs/remotes/origin/mob/master-green")
+}
+
+func TestCleanOptionsRequireRemote(t *testing.T) {
+	output, configuration := setup(t)
+	mockExit()
+	defer resetExit()
+
+	cleanRemote(configuration, []string{"--older-than", "30d"})
+
+	assertOutputContains(t, output, "the options of clean require --remote")
+}
+
+func TestCleanRemoteWithInvalidOptions(t *testing.T) {
+	_, configuration := setup(t)
+
+	_, err := parseCleanOptions([]string{"--remote", "--archive", "zip"}, configuration)
+	equals(t, "unknown archive 'zip', use 'tag' or 'bundle'", err.Error())
+
+	_, err = parseCleanOptions([]string{"--remote", "--older-than", "a month"}, configuration)
+	equals(t, true, strings.HasPrefix(err.Error(), "invalid age 'a month'"))
+
+	options, err := parseCleanOptions([]string{"--remote"}, configuration)
+	equals(t, nil, err)
+	equals(t, configuration.SessionStaleAfter, options.olderThan)
+}
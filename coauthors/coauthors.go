@@ -1,135 +1,126 @@
 package coauthors
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"path"
 	"regexp"
 	"sort"
 	"strings"
 
+	"github.com/remotemobprogramming/mob/v5/git"
 	"github.com/remotemobprogramming/mob/v5/say"
 )
 
 // Author is a coauthor "Full Name <email>"
 type Author = string
 
-func collectCoauthorsFromWipCommits(file *os.File, currentUserEmail string) []Author {
-	// Here we parse the SQUASH_MSG file for the list of authors on
-	// the WIP branch.  If this technique later turns out to be
-	// problematic, an alternative would be to instead fetch the
-	// authors' list from the git log, using e.g.:
-	//
-	// silentgit("log", fmt.Sprintf("%s..", currentBaseBranch), "--reverse", "--pretty=format:%an <%ae>")
-	//
-	// For details and background, see https://github.com/remotemobprogramming/mob/issues/81
-
-	coauthors := parseCoauthors(file)
+var authorMatcher = regexp.MustCompile(`^[^<>]*\S[^<>]*<([^<>\s]+)>$`)
+
+// Collect returns the authors and the existing Co-authored-by trailers of the commits selected by the revisions
+// (e.g. "main..mob/main") normalised via .mailmap and the registry, without the current user and without duplicates
+func Collect(gitClient *git.Client, registry Registry, currentUser Author, revisions ...string) []Author {
+	// %aN and %aE already respect .mailmap, the trailers are normalised with check-mailmap below
+	log := gitClient.Silent(append([]string{"log", "--format=%aN <%aE>%n%(trailers:key=Co-authored-by,valueonly)"}, revisions...)...)
+	authors := parseAuthors(log)
 	say.Debug("Parsed coauthors")
-	say.Debug(strings.Join(coauthors, ","))
+	say.Debug(strings.Join(authors, ","))
+	if len(authors) == 0 {
+		return authors
+	}
+
+	output, err := gitClient.SilentIgnoreFailure(append([]string{"check-mailmap"}, append(authors, currentUser)...)...)
+	if normalised := strings.Split(output, "\n"); err == nil && len(normalised) == len(authors)+1 {
+		authors, currentUser = normalised[:len(authors)], normalised[len(authors)]
+	}
+
+	return Normalise(registry, currentUser, authors)
+}
+
+// Normalise resolves the authors with the registry and removes the current user and duplicates
+func Normalise(registry Registry, currentUser Author, authors []Author) []Author {
+	var canonicalAuthors []Author
+	for _, author := range authors {
+		canonicalAuthors = append(canonicalAuthors, registry.Canonical(author))
+	}
+	say.Debug("Canonical coauthors")
+	say.Debug(strings.Join(canonicalAuthors, ","))
 
-	coauthors = removeElementsContaining(coauthors, currentUserEmail)
-	say.Debug("Parsed coauthors without committer")
-	say.Debug(strings.Join(coauthors, ","))
+	canonicalAuthors = removeAuthor(canonicalAuthors, registry.Canonical(currentUser))
+	say.Debug("Canonical coauthors without committer")
+	say.Debug(strings.Join(canonicalAuthors, ","))
 
-	coauthors = removeDuplicateValues(coauthors)
+	canonicalAuthors = removeDuplicateValues(canonicalAuthors)
 	say.Debug("Unique coauthors without committer")
-	say.Debug(strings.Join(coauthors, ","))
+	say.Debug(strings.Join(canonicalAuthors, ","))
 
-	sortByLength(coauthors)
+	sortByLength(canonicalAuthors)
 	say.Debug("Sorted unique coauthors without committer")
-	say.Debug(strings.Join(coauthors, ","))
+	say.Debug(strings.Join(canonicalAuthors, ","))
 
-	return coauthors
+	return canonicalAuthors
 }
 
-func parseCoauthors(file *os.File) []Author {
-	var coauthors []Author
+// Trailers formats the coauthors as Co-authored-by trailers, one per line
+func Trailers(coauthors []Author) string {
+	trailers := ""
+	for _, trailer := range TrailerLines(coauthors) {
+		trailers += trailer + "\n"
+	}
+	return trailers
+}
 
-	authorOrCoauthorMatcher := regexp.MustCompile("(?i).*(author)+.+<+.*>+")
-	scanner := bufio.NewScanner(file)
+// TrailerLines formats the coauthors as Co-authored-by trailers
+func TrailerLines(coauthors []Author) []string {
+	var trailers []string
+	for _, coauthor := range coauthors {
+		trailers = append(trailers, fmt.Sprintf("Co-authored-by: %s", coauthor))
+	}
+	return trailers
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if authorOrCoauthorMatcher.MatchString(line) {
-			author := stripToAuthor(line)
-			coauthors = append(coauthors, author)
+func parseAuthors(log string) []Author {
+	var authors []Author
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimSpace(line)
+		if authorMatcher.MatchString(line) {
+			authors = append(authors, line)
 		}
 	}
-	return coauthors
+	return authors
 }
 
-func stripToAuthor(line string) Author {
-	return strings.TrimSpace(strings.Join(strings.Split(line, ":")[1:], ""))
+func email(author Author) string {
+	if matches := authorMatcher.FindStringSubmatch(author); matches != nil {
+		return strings.ToLower(matches[1])
+	}
+	return strings.ToLower(author)
 }
 
 func sortByLength(slice []string) {
-	sort.Slice(slice, func(i, j int) bool {
+	sort.SliceStable(slice, func(i, j int) bool {
 		return len(slice[i]) < len(slice[j])
 	})
 }
 
-func removeElementsContaining(slice []string, containsFilter string) []string {
-	var result []string
-
+func removeAuthor(slice []Author, author Author) []Author {
+	var result []Author
 	for _, entry := range slice {
-		if !strings.Contains(entry, containsFilter) {
+		if email(entry) != email(author) {
 			result = append(result, entry)
 		}
 	}
 	return result
 }
 
+// keeps the first entry per email, ignoring the case of the email
 func removeDuplicateValues(slice []string) []string {
 	var result []string
 
 	keys := make(map[string]bool)
 	for _, entry := range slice {
-		if _, value := keys[entry]; !value {
-			keys[entry] = true
+		if _, value := keys[email(entry)]; !value {
+			keys[email(entry)] = true
 			result = append(result, entry)
 		}
 	}
 	return result
 }
-
-func AppendCoauthorsToSquashMsg(gitDir string, currentUserEmail string) error {
-	squashMsgPath := path.Join(gitDir, "SQUASH_MSG")
-	say.Debug("opening " + squashMsgPath)
-	file, err := os.OpenFile(squashMsgPath, os.O_APPEND|os.O_RDWR, 0644)
-	if err != nil {
-		if os.IsNotExist(err) {
-			say.Debug(squashMsgPath + " does not exist")
-			// No wip commits, nothing to squash, this isn't really an error
-			return nil
-		}
-		return err
-	}
-
-	defer file.Close()
-
-	// read from repo/.git/SQUASH_MSG
-	coauthors := collectCoauthorsFromWipCommits(file, currentUserEmail)
-
-	if len(coauthors) > 0 {
-		coauthorSuffix := createCommitMessage(coauthors)
-
-		// append to repo/.git/SQUASH_MSG
-		writer := bufio.NewWriter(file)
-		writer.WriteString(coauthorSuffix)
-		err = writer.Flush()
-	}
-
-	return err
-}
-
-func createCommitMessage(coauthors []Author) string {
-	commitMessage := "\n\n"
-	commitMessage += "# automatically added all co-authors from WIP commits\n"
-	commitMessage += "# add missing co-authors manually\n"
-	for _, coauthor := range coauthors {
-		commitMessage += fmt.Sprintf("Co-authored-by: %s\n", coauthor)
-	}
-	return commitMessage
-}
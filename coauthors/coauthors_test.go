@@ -5,20 +5,6 @@ import (
 	"testing"
 )
 
-func TestCreateCommitMessage(t *testing.T) {
-	expected := `
-
-# automatically added all co-authors from WIP commits
-# add missing co-authors manually
-Co-authored-by: Alice <alice@example.com>
-Co-authored-by: Bob <bob@example.com>
-`
-	actual := createCommitMessage([]Author{"Alice <alice@example.com>", "Bob <bob@example.com>"})
-	if actual != expected {
-		t.Errorf("expected %q, got %q", expected, actual)
-	}
-}
-
 func TestSortByLength(t *testing.T) {
 	slice := []string{"aa", "b"}
 
@@ -40,3 +26,36 @@ func TestRemoveDuplicateValues(t *testing.T) {
 		t.Errorf("expected %v, got %v", expected, actual)
 	}
 }
+
+func TestRemoveDuplicateValuesIgnoresCaseOfEmail(t *testing.T) {
+	slice := []Author{"Alice <alice@example.com>", "alice <Alice@Example.com>", "Bob <bob@example.com>"}
+
+	actual := removeDuplicateValues(slice)
+
+	expected := []Author{"Alice <alice@example.com>", "Bob <bob@example.com>"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestRemoveAuthorMatchesWholeEmail(t *testing.T) {
+	slice := []Author{"Alice <alice@example.com>", "Malice <malice@example.com>"}
+
+	actual := removeAuthor(slice, "alice <ALICE@example.com>")
+
+	expected := []Author{"Malice <malice@example.com>"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestParseAuthors(t *testing.T) {
+	log := "Alice <alice@example.com>\nBob <bob@example.com>\n\nnot an author\n<no-name@example.com>\n"
+
+	actual := parseAuthors(log)
+
+	expected := []Author{"Alice <alice@example.com>", "Bob <bob@example.com>"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,87 @@
+package coauthors
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// RegistryFileName is the team's alias file in the root of the repository. Each line maps comma separated aliases,
+// e.g. initials or the names and emails someone commits with on other machines, to the canonical author:
+//
+//	ab, alice, alice@laptop.local = Alice Bauer <alice@example.com>
+const RegistryFileName = ".mob-authors"
+
+// Registry resolves aliases to canonical authors
+type Registry struct {
+	authors map[string]Author
+}
+
+// ReadRegistry reads the aliases from the file, a missing file results in an empty registry
+func ReadRegistry(path string) (Registry, error) {
+	registry := Registry{authors: map[string]Author{}}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return registry, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		aliases, author, found := strings.Cut(line, "=")
+		author = strings.TrimSpace(author)
+		if !found || !authorMatcher.MatchString(author) {
+			return registry, errors.New(path + ":" + strconv.Itoa(lineNumber) + ": expected 'aliases = Name <email>', got '" + line + "'")
+		}
+		registry.add(Name(author), author)
+		registry.add(email(author), author)
+		for _, alias := range strings.Split(aliases, ",") {
+			registry.add(alias, author)
+		}
+	}
+	return registry, scanner.Err()
+}
+
+func (r Registry) add(alias string, author Author) {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	if alias != "" {
+		r.authors[alias] = author
+	}
+}
+
+// Lookup finds the canonical author for an alias, a name or an email
+func (r Registry) Lookup(alias string) (Author, bool) {
+	author, found := r.authors[strings.ToLower(strings.TrimSpace(alias))]
+	return author, found
+}
+
+// Canonical resolves the author by its email or else by its name, unknown authors are returned unchanged
+func (r Registry) Canonical(author Author) Author {
+	if canonical, found := r.Lookup(email(author)); found {
+		return canonical
+	}
+	if canonical, found := r.Lookup(Name(author)); found {
+		return canonical
+	}
+	return author
+}
+
+// Name returns the name of the author "Full Name <email>"
+func Name(author Author) string {
+	name, _, _ := strings.Cut(author, "<")
+	return strings.TrimSpace(name)
+}
+
+// IsAuthor tells whether the value has the form "Full Name <email>"
+func IsAuthor(value string) bool {
+	return authorMatcher.MatchString(strings.TrimSpace(value))
+}
@@ -0,0 +1,79 @@
+package coauthors
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func writeRegistry(t *testing.T, content string) string {
+	path := t.TempDir() + "/" + RegistryFileName
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadRegistry(t *testing.T) {
+	path := writeRegistry(t, "# team\n\nab, alice-laptop, alice@laptop.local = Alice Bauer <alice@example.com>\ncd = Carol Diaz <carol@example.com>\n")
+
+	registry, err := ReadRegistry(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	for alias, expected := range map[string]Author{
+		"ab":                 "Alice Bauer <alice@example.com>",
+		"AB":                 "Alice Bauer <alice@example.com>",
+		"alice-laptop":       "Alice Bauer <alice@example.com>",
+		"alice@laptop.local": "Alice Bauer <alice@example.com>",
+		"Alice Bauer":        "Alice Bauer <alice@example.com>",
+		"carol@example.com":  "Carol Diaz <carol@example.com>",
+	} {
+		if actual, found := registry.Lookup(alias); !found || actual != expected {
+			t.Errorf("expected %q for %q, got %q", expected, alias, actual)
+		}
+	}
+}
+
+func TestReadRegistryWithoutFile(t *testing.T) {
+	registry, err := ReadRegistry(t.TempDir() + "/" + RegistryFileName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := registry.Canonical("Alice <alice@example.com>"); actual != "Alice <alice@example.com>" {
+		t.Errorf("expected unknown author to stay unchanged, got %q", actual)
+	}
+}
+
+func TestReadRegistryWithInvalidLine(t *testing.T) {
+	path := writeRegistry(t, "ab = Alice Bauer\n")
+
+	_, err := ReadRegistry(path)
+
+	if err == nil || err.Error() != path+":1: expected 'aliases = Name <email>', got 'ab = Alice Bauer'" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	registry, _ := ReadRegistry(writeRegistry(t, "alice-laptop, alice@laptop.local = Alice Bauer <alice@example.com>\n"))
+
+	for _, author := range []Author{"alice-laptop <alice@home.local>", "Alice <alice@laptop.local>", "Alice <ALICE@example.com>"} {
+		if actual := registry.Canonical(author); actual != "Alice Bauer <alice@example.com>" {
+			t.Errorf("expected canonical author for %q, got %q", author, actual)
+		}
+	}
+}
+
+func TestNormalise(t *testing.T) {
+	registry, _ := ReadRegistry(writeRegistry(t, "ab, alice-laptop = Alice Bauer <alice@example.com>\n"))
+
+	actual := Normalise(registry, "Bob <bob@example.com>", []Author{"alice-laptop <alice@laptop.local>", "Bob <bob@example.com>", "Alice Bauer <alice@example.com>", "Carol <carol@example.com>"})
+
+	expected := []Author{"Carol <carol@example.com>", "Alice Bauer <alice@example.com>"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
 )
 
 func TestStartDoneCoAuthors(t *testing.T) {
@@ -44,3 +47,125 @@ func TestStartDoneCoAuthors(t *testing.T) {
 	// include everyone else in commit order after removing duplicates
 	assertOutputContains(t, &output, "\nCo-authored-by: bob <bob@example.com>\nCo-authored-by: alice <alice@example.com>\nCo-authored-by: localother <localother@example.com>\n")
 }
+
+func TestDoneNoSquashAddsCoAuthorsAsTrailers(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.NoSquash
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	done(configuration)
+
+	output := readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG"))
+	assertOutputContains(t, &output, "Co-authored-by: alice <alice@example.com>\n")
+	assertOutputNotContains(t, &output, "Co-authored-by: local <local@example.com>")
+}
+
+func TestDoneSquashWipAddsCoAuthorsAsTrailers(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.SquashWip
+
+	setWorkingDir(tempDir + "/bob")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	done(configuration)
+
+	output := readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG"))
+	assertOutputContains(t, &output, "Co-authored-by: bob <bob@example.com>\n")
+}
+
+func TestDoneNoSquashEndingOnManualCommitAddsCoAuthorsToLastCommit(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.NoSquash
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "manual commit")
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	equals(t, "manual commit\n\nCo-authored-by: alice <alice@example.com>", lastCommitMessage())
+	equals(t, "local", silentgit("log", "-1", "--pretty=format:%an"))
+	assertCleanGitStatus(t)
+}
+
+func TestDoneSquashWipEndingOnManualCommitAddsCoAuthorsOfSquashedWipCommits(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.SquashWip
+
+	setWorkingDir(tempDir + "/bob")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "manual commit")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	equals(t, "manual commit\n\nCo-authored-by: bob <bob@example.com>\nCo-authored-by: local <local@example.com>", lastCommitMessage())
+	equals(t, "alice", silentgit("log", "-1", "--pretty=format:%an"))
+}
+
+func TestDoneKeepsExistingCoAuthoredByTrailers(t *testing.T) {
+	_, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	git("add", "--all")
+	git("commit", "--message", "pairing\n\nCo-authored-by: Carol <carol@example.com>")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	done(configuration)
+
+	output := readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG"))
+	assertOutputContains(t, &output, "\nCo-authored-by: Carol <carol@example.com>\n")
+}
+
+func TestDoneNormalisesCoAuthorsWithMailmap(t *testing.T) {
+	_, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	start(configuration)
+	git("config", "--local", "user.email", "ALICE@example.com")
+	createFile(t, "file2.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, ".mailmap", "Alice Example <alice@example.com> <ALICE@example.com>\nAlice Example <alice@example.com> alice <alice@example.com>\n")
+	done(configuration)
+
+	output := readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG"))
+	equals(t, 1, strings.Count(output, "Co-authored-by:"))
+	assertOutputContains(t, &output, "Co-authored-by: Alice Example <alice@example.com>\n")
+}
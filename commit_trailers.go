@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/remotemobprogramming/mob/v5/coauthors"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	mobgit "github.com/remotemobprogramming/mob/v5/git"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// options for the commits mob creates, e.g. '--gpg-sign --signoff'
+func signingOptions(configuration config.Configuration) []string {
+	var options []string
+	for _, option := range []string{mobgit.SignOption(configuration), mobgit.SignOffOption(configuration)} {
+		if option != "" {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// the Signed-off-by trailer of the current user and MOB_COMMIT_TRAILERS
+func configuredTrailers(configuration config.Configuration) []string {
+	var trailers []string
+	if configuration.SignOff {
+		trailers = append(trailers, "Signed-off-by: "+currentAuthor())
+	}
+	return append(trailers, configuration.CommitTrailerLines()...)
+}
+
+// adds co-authors and configured trailers to the message file in the git dir, e.g. SQUASH_MSG for the final commit
+func appendFinalCommitTrailers(configuration config.Configuration, messageFile string, sessionCoauthors []coauthors.Author) {
+	trailers := append(coauthors.TrailerLines(sessionCoauthors), configuredTrailers(configuration)...)
+	if err := gitClient.AppendTrailers(gitDir()+"/"+messageFile, trailers); err != nil {
+		say.Warning("Could not add trailers to the commit message: " + err.Error())
+	}
+}
+
+// the session ended with a commit, e.g. a manual commit with MOB_DONE_SQUASH=no-squash, so there is no final commit to
+// write: the co-authors of the session are added to the last commit instead, the commit message template is not used
+func finishWithLastCommit(configuration config.Configuration, baseBranch Branch, sessionCoauthors []coauthors.Author, hasCommitMessageTemplate bool) {
+	if silentgit("rev-parse", "HEAD") == silentgit("rev-parse", baseBranch.remote(configuration).Name) {
+		return
+	}
+	lastCommit := readCommit("HEAD")
+	subject, _, _ := strings.Cut(lastCommit.Message, "\n")
+	if hasCommitMessageTemplate {
+		say.Warning("MOB_DONE_COMMIT_MESSAGE_TEMPLATE is not used, because the session ended with the commit '" + subject + "' and there is no final commit to write.")
+	}
+	lastCommitCoauthors := coauthors.Normalise(readAuthorRegistry(), lastCommit.author(), append(sessionCoauthors, currentAuthor()))
+	if len(lastCommitCoauthors) == 0 {
+		return
+	}
+	messageFile := gitDir() + "/MOB_DONE_MSG"
+	defer os.Remove(messageFile)
+	if err := os.WriteFile(messageFile, []byte(lastCommit.Message+"\n"), 0644); err != nil {
+		say.Warning("Could not add the co-authors to the last commit: " + err.Error())
+		return
+	}
+	if err := gitClient.AppendTrailers(messageFile, coauthors.TrailerLines(lastCommitCoauthors)); err != nil {
+		say.Warning("Could not add the co-authors to the last commit: " + err.Error())
+		return
+	}
+	if message, _ := os.ReadFile(messageFile); strings.TrimSpace(string(message)) == strings.TrimSpace(lastCommit.Message) {
+		return
+	}
+	gitWithoutEmptyStrings("commit", "--amend", "--allow-empty", "--file", messageFile, gitHooksOption(configuration), mobgit.SignOption(configuration))
+	say.Info("added the co-authors of the session to the last commit '" + subject + "'")
+}
+
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9-]+:`)
+
+// appends the trailers to the message, joining an existing trailer block in the last paragraph
+func appendTrailers(message string, trailers []string) string {
+	if len(trailers) == 0 {
+		return message
+	}
+	message = strings.TrimRight(message, "\n")
+	paragraphs := strings.Split(message, "\n\n")
+	lastParagraph := paragraphs[len(paragraphs)-1]
+	separator := "\n\n"
+	if len(paragraphs) > 1 && isTrailerBlock(lastParagraph) {
+		separator = "\n"
+	}
+	return message + separator + strings.Join(trailers, "\n")
+}
+
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		if !trailerLine.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// the command to create the final commit after 'mob done'
+func finalCommitCommand(configuration config.Configuration) string {
+	if configuration.SignCommits {
+		return "git commit --gpg-sign"
+	}
+	return "git commit"
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+// signs with a fresh ssh key, so that the tests don't depend on a gpg setup
+func useSshSigningKey(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not available")
+	}
+	key := filepath.Join(t.TempDir(), "signing-key")
+	if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("could not create signing key: %s", output)
+	}
+	git("config", "--local", "gpg.format", "ssh")
+	git("config", "--local", "user.signingkey", key+".pub")
+}
+
+func TestNextSignsWipCommits(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.SignCommits = true
+
+	setWorkingDir(tempDir + "/local")
+	useSshSigningKey(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	equals(t, true, isSignedCommit("origin/mob-session"))
+}
+
+func TestNextAddsSignOffAndCustomTrailers(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.SignOff = true
+	configuration.CommitTrailers = "Team: green"
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	equals(t, configuration.WipCommitMessage+"\n\nlastFile:file1.txt\nMob-Last-File-Line: 1\nMob-Driver: local <local@example.com>\nMob-Rotation: 1\nTeam: green\nSigned-off-by: local <local@example.com>", silentgit("log", "-1", "--pretty=format:%B", "origin/mob-session"))
+}
+
+func TestDoneAddsSignOffAndTrailersToFinalCommitMessage(t *testing.T) {
+	output, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	configuration.SignCommits = true
+	configuration.SignOff = true
+	configuration.CommitTrailers = "Team: green\nRefs: #42"
+	useSshSigningKey(t)
+	start(configuration)
+	done(configuration)
+
+	squashMsg := readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG"))
+	assertOutputContains(t, &squashMsg, "\nCo-authored-by: alice <alice@example.com>\nSigned-off-by: local <local@example.com>\nTeam: green\nRefs: #42\n")
+	assertOutputContains(t, output, "git commit --gpg-sign")
+}
+
+func TestDoneNoSquashSignsMergeCommit(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.NoSquash
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFileAndCommitIt(t, "file1.txt", "contentIrrelevant", "manual commit")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "diverging commit")
+	git("push")
+
+	setWorkingDir(tempDir + "/local")
+	configuration.SignCommits = true
+	useSshSigningKey(t)
+	start(configuration)
+	done(configuration)
+
+	equals(t, 2, len(strings.Fields(silentgit("log", "-1", "--format=%P")))) // merge commit
+	equals(t, true, isSignedCommit("HEAD"))
+}
+
+func TestSquashWipSignsRewrittenSignedCommits(t *testing.T) {
+	_, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/local")
+	useSshSigningKey(t)
+	wipCommit(t, configuration, "file1.txt")
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	git("add", "--all")
+	git("commit", "--gpg-sign", "--message", "signed manual commit")
+	unsignedManualCommit := "unsigned manual commit"
+	createFileAndCommitIt(t, "file3.txt", "contentIrrelevant", unsignedManualCommit)
+
+	squashWip(configuration)
+
+	equals(t, []string{unsignedManualCommit, "signed manual commit"}, commitsOnCurrentBranch(configuration))
+	equals(t, true, isSignedCommit("HEAD^"))
+	equals(t, false, isSignedCommit("HEAD"))
+}
+
+func TestDoneSquashWipKeepsWipBranchIfRewrittenCommitCannotBeSigned(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.DoneSquash = config.SquashWip
+
+	setWorkingDir(tempDir + "/local")
+	useSshSigningKey(t)
+	wipCommit(t, configuration, "file1.txt")
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	git("add", "--all")
+	git("commit", "--gpg-sign", "--message", "signed manual commit")
+	createFile(t, "file3.txt", "contentIrrelevant")
+	before := silentgit("rev-parse", "HEAD")
+	git("config", "--local", "user.signingkey", filepath.Join(t.TempDir(), "missing-key.pub"))
+
+	mockExit()
+	done(configuration)
+
+	assertOutputContains(t, output, "could not sign the rewritten commit of 'signed manual commit'")
+	assertOnBranch(t, "mob-session")
+	equals(t, before, silentgit("rev-parse", "HEAD"))
+	assertGitStatus(t, GitStatus{"file3.txt": "??"})
+}
+
+func TestAppendTrailers(t *testing.T) {
+	equals(t, "subject", appendTrailers("subject", nil))
+	equals(t, "subject\n\nTeam: green", appendTrailers("subject\n", []string{"Team: green"}))
+	equals(t, "subject\n\nCo-authored-by: alice <alice@example.com>\nTeam: green", appendTrailers("subject\n\nCo-authored-by: alice <alice@example.com>\n", []string{"Team: green"}))
+	equals(t, "subject\n\nbody: not a trailer block\nreally\n\nTeam: green", appendTrailers("subject\n\nbody: not a trailer block\nreally", []string{"Team: green"}))
+}
@@ -0,0 +1,352 @@
+package configuration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type option struct {
+	long     string
+	short    string
+	hasValue bool
+	// options that are interpreted by the command itself are passed on as parameters
+	passThrough bool
+	apply       func(configuration *Configuration, value string)
+}
+
+type parsedOption struct {
+	option option
+	value  string
+}
+
+func (o option) String() string {
+	return "--" + o.long
+}
+
+var (
+	optionDebug = option{long: "debug"} // already parsed by say.TurnOnDebuggingByArgs
+	// applied before all other options, see ParseArgs
+	optionProfile = option{long: "profile", hasValue: true}
+	optionHelp    = option{long: "help", short: "h", passThrough: true}
+
+	optionIncludeUncommittedChanges = option{long: "include-uncommitted-changes", short: "i", apply: func(c *Configuration, _ string) {
+		c.HandleUncommittedChanges = IncludeChanges
+	}}
+	optionDiscardUncommittedChanges = option{long: "discard-uncommitted-changes", short: "d", apply: func(c *Configuration, _ string) {
+		c.HandleUncommittedChanges = DiscardChanges
+	}}
+	optionBranch = option{long: "branch", short: "b", hasValue: true, apply: func(c *Configuration, value string) {
+		c.WipBranchQualifier = value
+	}}
+	optionCreate = option{long: "create", short: "c", apply: func(c *Configuration, _ string) {
+		c.StartCreate = true
+	}}
+	optionJoin = option{long: "join", short: "j", apply: func(c *Configuration, _ string) {
+		c.StartJoin = true
+	}}
+	optionStack = option{long: "stack", apply: func(c *Configuration, _ string) {
+		c.StartStack = true
+	}}
+	optionSync = option{long: "sync", apply: func(c *Configuration, _ string) {
+		c.StartSync = true
+	}}
+	optionSyncRebase = option{long: "rebase", apply: func(c *Configuration, _ string) {
+		c.SyncRebase = true
+	}}
+	optionSyncMerge = option{long: "merge", apply: func(c *Configuration, _ string) {
+		c.SyncRebase = false
+	}}
+	optionRoom = option{long: "room", hasValue: true, apply: func(c *Configuration, value string) {
+		c.TimerRoom = value
+	}}
+	optionStay = option{long: "stay", short: "s", apply: func(c *Configuration, _ string) {
+		c.NextStay = true
+	}}
+	optionReturnToBaseBranch = option{long: "return-to-base-branch", short: "r", apply: func(c *Configuration, _ string) {
+		c.NextStay = false
+	}}
+	optionHandover = option{long: "handover", apply: func(c *Configuration, _ string) {
+		c.NextHandover = true
+	}}
+	optionNoHandover = option{long: "no-handover", apply: func(c *Configuration, _ string) {
+		c.NextHandover = false
+	}}
+	optionInteractive = option{long: "interactive", apply: func(c *Configuration, _ string) {
+		c.NextInteractive = true
+	}}
+	optionMessage = option{long: "message", short: "m", hasValue: true, apply: func(c *Configuration, value string) {
+		c.WipCommitMessage = value
+	}}
+	optionSquash = option{long: "squash", apply: func(c *Configuration, _ string) {
+		c.DoneSquash = Squash
+	}}
+	optionNoSquash = option{long: "no-squash", apply: func(c *Configuration, _ string) {
+		c.DoneSquash = NoSquash
+	}}
+	optionSquashWip = option{long: "squash-wip", apply: func(c *Configuration, _ string) {
+		c.DoneSquash = SquashWip
+	}}
+	optionRebase = option{long: "rebase", apply: func(c *Configuration, _ string) {
+		c.DoneSquash = Rebase
+	}}
+	optionPullRequest = option{long: "pr", apply: func(c *Configuration, _ string) {
+		c.DonePullRequest = true
+	}}
+	optionDeleteRemoteWipBranch = option{long: "delete-remote-wip-branch", apply: func(c *Configuration, _ string) {
+		c.ResetDeleteRemoteWipBranch = true
+	}}
+	optionArchive = option{long: "archive", apply: func(c *Configuration, _ string) {
+		c.ResetArchive = true
+	}}
+	optionNoArchive = option{long: "no-archive", apply: func(c *Configuration, _ string) {
+		c.ResetArchive = false
+	}}
+)
+
+var globalOptions = []option{optionDebug, optionProfile, optionHelp}
+
+var commandOptions = map[string][]option{
+	"start":   {optionIncludeUncommittedChanges, optionDiscardUncommittedChanges, optionBranch, optionCreate, optionJoin, optionStack, optionSync, optionRoom},
+	"next":    {optionStay, optionReturnToBaseBranch, optionMessage, optionHandover, optionNoHandover, optionInteractive},
+	"done":    {optionSquash, optionNoSquash, optionSquashWip, optionRebase, optionPullRequest, {long: "continue", passThrough: true}, {long: "abort", passThrough: true}},
+	"reset":   {optionBranch, optionDeleteRemoteWipBranch, optionArchive, optionNoArchive},
+	"timer":   {optionRoom},
+	"break":   {optionRoom},
+	"goal":    {{long: "delete", passThrough: true}},
+	"with":    {{long: "clear", passThrough: true}},
+	"init":    {{long: "yes", short: "y", passThrough: true}, {long: "project", passThrough: true}, {long: "user", passThrough: true}, {long: "remote", hasValue: true, passThrough: true}, optionRoom, optionSquash, optionNoSquash, optionSquashWip, optionRebase},
+	"branch":  {{long: "json", passThrough: true}},
+	"archive": {},
+	"migrate": {optionBranch},
+	"sync":    {optionSyncRebase, optionSyncMerge},
+	"clean":   {{long: "remote", passThrough: true}, {long: "older-than", hasValue: true, passThrough: true}, {long: "archive", hasValue: true, passThrough: true}, {long: "yes", short: "y", passThrough: true}},
+	"config":  {},
+	"fetch":   {},
+	"status":  {},
+	"moo":     {},
+	"version": {},
+	"help":    {},
+}
+
+var commandAliases = map[string]string{
+	"s": "start",
+	"n": "next",
+	"d": "done",
+	"b": "branch",
+	"t": "timer",
+	"g": "goal",
+}
+
+// commands that may be given in the form of an option
+var optionLikeCommands = []string{"--version", "-v", "--help", "-h"}
+
+// ParseArgs parses POSIX style arguments: options of the command may be given as '--branch green', '--branch=green',
+// '-b green' or '-bgreen', short options may be combined ('-ic') and '--' ends the options.
+func ParseArgs(args []string, configuration Configuration) (command string, parameters []string, newConfiguration Configuration, err error) {
+	newConfiguration = configuration
+
+	command, parameters, options, err := parseOptions(args, configuration.CliName)
+	if err != nil {
+		return command, parameters, newConfiguration, err
+	}
+
+	// the profile is applied first, so that all other options take precedence over it
+	for _, parsed := range options {
+		if parsed.option.long == optionProfile.long && parsed.value != newConfiguration.Profile {
+			newConfiguration = applyProfile(newConfiguration, parsed.value)
+		}
+	}
+
+	for _, parsed := range options {
+		if parsed.option.apply != nil {
+			parsed.option.apply(&newConfiguration, parsed.value)
+		}
+	}
+	return command, parameters, newConfiguration, nil
+}
+
+// IsOptionGiven tells whether the option with the given long name was passed in args, in any of its forms
+func IsOptionGiven(args []string, long string) bool {
+	_, _, options, _ := parseOptions(args, "")
+	for _, parsed := range options {
+		if parsed.option.long == long {
+			return true
+		}
+	}
+	return false
+}
+
+func parseOptions(args []string, cliName string) (command string, parameters []string, options []parsedOption, err error) {
+	knownOptions := globalOptions
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			parameters = append(parameters, args[i+1:]...)
+			break
+		}
+
+		if command == "" && stringContains(optionLikeCommands, arg) {
+			command = arg
+			continue
+		}
+
+		if strings.HasPrefix(arg, "--") {
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			opt, found := findOption(knownOptions, func(o option) bool { return o.long == name })
+			if !found {
+				return command, parameters, options, unknownOptionError("--"+name, command, knownOptions, cliName)
+			}
+			if hasValue && !opt.hasValue {
+				return command, parameters, options, fmt.Errorf("option '%s' does not take a value", opt)
+			}
+			if opt.hasValue && !hasValue {
+				if i+1 == len(args) {
+					return command, parameters, options, fmt.Errorf("option '%s' requires a value", opt)
+				}
+				value = args[i+1]
+				i++ // skip consumed parameter
+			}
+			options = appendOption(options, opt, value, &parameters)
+			continue
+		}
+
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			shortOptions := arg[1:]
+			for j := 0; j < len(shortOptions); j++ {
+				name := shortOptions[j : j+1]
+				opt, found := findOption(knownOptions, func(o option) bool { return o.short == name })
+				if !found {
+					return command, parameters, options, unknownOptionError("-"+name, command, knownOptions, cliName)
+				}
+				value := ""
+				if opt.hasValue {
+					if j+1 < len(shortOptions) {
+						value = shortOptions[j+1:]
+					} else if i+1 < len(args) {
+						value = args[i+1]
+						i++ // skip consumed parameter
+					} else {
+						return command, parameters, options, fmt.Errorf("option '-%s' requires a value", name)
+					}
+					j = len(shortOptions)
+				}
+				options = appendOption(options, opt, value, &parameters)
+			}
+			continue
+		}
+
+		if command != "" {
+			parameters = append(parameters, arg)
+			continue
+		}
+
+		command = arg
+		currentCommandOptions, known := commandOptions[resolveCommandAlias(command)]
+		if !known {
+			// unknown commands show the help, so we don't complain about their options
+			parameters = append(parameters, args[i+1:]...)
+			break
+		}
+		knownOptions = append(append([]option{}, globalOptions...), currentCommandOptions...)
+	}
+
+	return command, parameters, options, nil
+}
+
+func appendOption(options []parsedOption, opt option, value string, parameters *[]string) []parsedOption {
+	if opt.passThrough {
+		*parameters = append(*parameters, opt.String())
+		if opt.hasValue {
+			*parameters = append(*parameters, value)
+		}
+	}
+	return append(options, parsedOption{option: opt, value: value})
+}
+
+func findOption(options []option, matches func(option) bool) (option, bool) {
+	for _, opt := range options {
+		if matches(opt) {
+			return opt, true
+		}
+	}
+	return option{}, false
+}
+
+func resolveCommandAlias(command string) string {
+	if resolved, isAlias := commandAliases[command]; isAlias {
+		return resolved
+	}
+	return command
+}
+
+func unknownOptionError(name string, command string, knownOptions []option, cliName string) error {
+	isLong := strings.HasPrefix(name, "--")
+	matchesName := func(o option) bool {
+		if isLong {
+			return "--"+o.long == name
+		}
+		return o.short != "" && "-"+o.short == name
+	}
+
+	var commandsWithOption []string
+	for otherCommand, options := range commandOptions {
+		if _, found := findOption(options, matchesName); found {
+			commandsWithOption = append(commandsWithOption, otherCommand)
+		}
+	}
+	sort.Strings(commandsWithOption)
+
+	if command == "" {
+		if len(commandsWithOption) > 0 {
+			return fmt.Errorf("option '%s' must be given after the command, it is supported by: %s", name, strings.Join(commandsWithOption, ", "))
+		}
+		return fmt.Errorf("unknown option '%s'", name)
+	}
+
+	commandLine := strings.TrimSpace(cliName + " " + command)
+	if len(commandsWithOption) > 0 {
+		return fmt.Errorf("option '%s' is not supported by '%s', it is supported by: %s", name, commandLine, strings.Join(commandsWithOption, ", "))
+	}
+	if isLong {
+		if suggestion := suggestOption(strings.TrimPrefix(name, "--"), knownOptions); suggestion != "" {
+			return fmt.Errorf("unknown option '%s' for '%s', did you mean '%s'?", name, commandLine, suggestion)
+		}
+	}
+	return fmt.Errorf("unknown option '%s' for '%s'", name, commandLine)
+}
+
+func suggestOption(name string, knownOptions []option) string {
+	suggestion := ""
+	bestDistance := 3 // suggest only options that differ by at most two edits
+	for _, opt := range knownOptions {
+		distance := levenshteinDistance(name, opt.long)
+		if distance < bestDistance {
+			bestDistance = distance
+			suggestion = opt.String()
+		}
+	}
+	return suggestion
+}
+
+func levenshteinDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
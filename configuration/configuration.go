@@ -2,17 +2,26 @@ package configuration
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/remotemobprogramming/mob/v5/input"
 	"github.com/remotemobprogramming/mob/v5/say"
+	"github.com/remotemobprogramming/mob/v5/workdir"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	Squash    = "squash"
 	NoSquash  = "no-squash"
 	SquashWip = "squash-wip"
+	Rebase    = "rebase"
 )
 
 const (
@@ -21,6 +30,34 @@ const (
 	FailWithError  = "fail-with-error"
 )
 
+// ParseAge parses ages like "30d", "2w", "12h" or "90m"
+func ParseAge(age string) (time.Duration, error) {
+	age = strings.TrimSpace(age)
+	units := map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	if len(age) < 2 {
+		return 0, fmt.Errorf("invalid age '%s', expected e.g. '30d', '2w', '12h' or '90m'", age)
+	}
+	unit, found := units[age[len(age)-1:]]
+	number, err := strconv.Atoi(age[:len(age)-1])
+	if !found || err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid age '%s', expected e.g. '30d', '2w', '12h' or '90m'", age)
+	}
+	return time.Duration(number) * unit, nil
+}
+
+// SessionStaleAfterDuration is the age of the last commit after which a session is stale
+func (c Configuration) SessionStaleAfterDuration() time.Duration {
+	age, err := ParseAge(c.SessionStaleAfter)
+	if err != nil {
+		say.Warning("MOB_SESSION_STALE_AFTER: " + err.Error())
+		age, _ = ParseAge(GetDefaultConfiguration().SessionStaleAfter)
+	}
+	return age
+}
+
+// AllFiles as MOB_OPEN_FILES opens all files of the last wip commit
+const AllFiles = "all"
+
 type Configuration struct {
 	CliName                        string // override with MOB_CLI_NAME
 	RemoteName                     string // override with MOB_REMOTE_NAME
@@ -29,28 +66,47 @@ type Configuration struct {
 	SkipCiPushOptionEnabled        bool   // override with MOB_SKIP_CI_PUSH_OPTION_ENABLED
 	GitHooksEnabled                bool   // override with MOB_GIT_HOOKS_ENABLED
 	RequireCommitMessage           bool   // override with MOB_REQUIRE_COMMIT_MESSAGE
+	SignCommits                    bool   // override with MOB_SIGN_COMMITS
+	SignOff                        bool   // override with MOB_SIGN_OFF
+	CommitTrailers                 string // override with MOB_COMMIT_TRAILERS
 	VoiceCommand                   string // override with MOB_VOICE_COMMAND
 	VoiceMessage                   string // override with MOB_VOICE_MESSAGE
 	NotifyCommand                  string // override with MOB_NOTIFY_COMMAND
 	NotifyMessage                  string // override with MOB_NOTIFY_MESSAGE
 	NextStay                       bool   // override with MOB_NEXT_STAY
+	NextHandover                   bool   // override with MOB_NEXT_HANDOVER
+	NextInteractive                bool
 	HandleUncommittedChanges       string
 	StartCreate                    bool // override with MOB_START_CREATE variable
 	StartJoin                      bool
-	StashName                      string // override with MOB_STASH_NAME
-	WipBranchQualifier             string // override with MOB_WIP_BRANCH_QUALIFIER
-	WipBranchQualifierSeparator    string // override with MOB_WIP_BRANCH_QUALIFIER_SEPARATOR
-	WipBranchPrefix                string // override with MOB_WIP_BRANCH_PREFIX
-	DoneSquash                     string // override with MOB_DONE_SQUASH
-	OpenCommand                    string // override with MOB_OPEN_COMMAND
-	Timer                          string // override with MOB_TIMER
-	TimerRoom                      string // override with MOB_TIMER_ROOM
-	TimerLocal                     bool   // override with MOB_TIMER_LOCAL
-	TimerRoomUseWipBranchQualifier bool   // override with MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER
-	TimerUser                      string // override with MOB_TIMER_USER
-	TimerUrl                       string // override with MOB_TIMER_URL
-	TimerInsecure                  bool   // override with MOB_TIMER_INSECURE
-	ResetDeleteRemoteWipBranch     bool   // override with MOB_RESET_DELETE_REMOTE_WIP_BRANCH
+	StartStack                     bool
+	StartSync                      bool     // override with MOB_START_SYNC
+	SyncRebase                     bool     // override with MOB_SYNC_REBASE
+	StashName                      string   // override with MOB_STASH_NAME
+	WipBranchQualifier             string   // override with MOB_WIP_BRANCH_QUALIFIER
+	WipBranchQualifierSeparator    string   // override with MOB_WIP_BRANCH_QUALIFIER_SEPARATOR
+	WipBranchPrefix                string   // override with MOB_WIP_BRANCH_PREFIX
+	DoneSquash                     string   // override with MOB_DONE_SQUASH
+	DonePullRequest                bool     // override with MOB_DONE_PULL_REQUEST
+	DoneCommitMessageTemplate      string   // override with MOB_DONE_COMMIT_MESSAGE_TEMPLATE
+	DoneTicketPattern              string   // override with MOB_DONE_TICKET_PATTERN
+	PullRequestProvider            string   // override with MOB_PULL_REQUEST_PROVIDER
+	PullRequestApiUrl              string   // override with MOB_PULL_REQUEST_API_URL
+	OpenCommand                    string   // override with MOB_OPEN_COMMAND
+	OpenFiles                      string   // override with MOB_OPEN_FILES
+	Timer                          string   // override with MOB_TIMER
+	TimerRoom                      string   // override with MOB_TIMER_ROOM
+	TimerLocal                     bool     // override with MOB_TIMER_LOCAL
+	TimerRoomUseWipBranchQualifier bool     // override with MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER
+	TimerUser                      string   // override with MOB_TIMER_USER
+	TimerUrl                       string   // override with MOB_TIMER_URL
+	TimerInsecure                  bool     // override with MOB_TIMER_INSECURE
+	ResetDeleteRemoteWipBranch     bool     // override with MOB_RESET_DELETE_REMOTE_WIP_BRANCH
+	ResetArchive                   bool     // override with MOB_RESET_ARCHIVE
+	LegacyMobSession               bool     // override with MOB_LEGACY_MOB_SESSION
+	SessionStaleAfter              string   // override with MOB_SESSION_STALE_AFTER
+	Profile                        string   // override with MOB_PROFILE
+	TrustedProjectSettings         []string // add with MOB_TRUSTED_PROJECT_SETTING in the user configuration file
 }
 
 func (c Configuration) Mob(command string) string {
@@ -73,23 +129,64 @@ func (c Configuration) IsWipCommitMessage(line string) bool {
 	return strings.HasPrefix(line, c.WipCommitMessage)
 }
 
+// CommitTrailers one per line, e.g. "Team: green\nRefs: #42"
+func (c Configuration) CommitTrailerLines() []string {
+	var trailers []string
+	for _, line := range strings.Split(c.CommitTrailers, "\n") {
+		if strings.Contains(line, ":") {
+			trailers = append(trailers, strings.TrimSpace(line))
+		}
+	}
+	return trailers
+}
+
 func (c Configuration) IsOpenCommandGiven() bool {
 	return strings.TrimSpace(c.OpenCommand) != ""
 }
 
+// OpenAllFiles is true if all files of the last wip commit should be opened
+func (c Configuration) OpenAllFiles() bool {
+	return strings.TrimSpace(c.OpenFiles) == AllFiles
+}
+
+// OpenFilesLimit is the number of most recently modified files to open, at least 1
+func (c Configuration) OpenFilesLimit() int {
+	limit, err := strconv.Atoi(strings.TrimSpace(c.OpenFiles))
+	if err != nil || limit < 1 {
+		return 1
+	}
+	return limit
+}
+
 func Config(c Configuration) {
 	say.Say("MOB_CLI_NAME" + "=" + quote(c.CliName))
+	say.Say("MOB_COMMIT_TRAILERS" + "=" + quote(c.CommitTrailers))
+	say.Say("MOB_DONE_COMMIT_MESSAGE_TEMPLATE" + "=" + quote(c.DoneCommitMessageTemplate))
+	say.Say("MOB_DONE_PULL_REQUEST" + "=" + strconv.FormatBool(c.DonePullRequest))
 	say.Say("MOB_DONE_SQUASH" + "=" + string(c.DoneSquash))
+	say.Say("MOB_DONE_TICKET_PATTERN" + "=" + quote(c.DoneTicketPattern))
 	say.Say("MOB_GIT_HOOKS_ENABLED" + "=" + strconv.FormatBool(c.GitHooksEnabled))
+	say.Say("MOB_LEGACY_MOB_SESSION" + "=" + strconv.FormatBool(c.LegacyMobSession))
+	say.Say("MOB_NEXT_HANDOVER" + "=" + strconv.FormatBool(c.NextHandover))
 	say.Say("MOB_NEXT_STAY" + "=" + strconv.FormatBool(c.NextStay))
 	say.Say("MOB_NOTIFY_COMMAND" + "=" + quote(c.NotifyCommand))
 	say.Say("MOB_NOTIFY_MESSAGE" + "=" + quote(c.NotifyMessage))
 	say.Say("MOB_OPEN_COMMAND" + "=" + quote(c.OpenCommand))
+	say.Say("MOB_OPEN_FILES" + "=" + quote(c.OpenFiles))
+	say.Say("MOB_PROFILE" + "=" + quote(c.Profile))
+	say.Say("MOB_PULL_REQUEST_API_URL" + "=" + quote(c.PullRequestApiUrl))
+	say.Say("MOB_PULL_REQUEST_PROVIDER" + "=" + quote(c.PullRequestProvider))
 	say.Say("MOB_REMOTE_NAME" + "=" + quote(c.RemoteName))
 	say.Say("MOB_REQUIRE_COMMIT_MESSAGE" + "=" + strconv.FormatBool(c.RequireCommitMessage))
+	say.Say("MOB_RESET_ARCHIVE" + "=" + strconv.FormatBool(c.ResetArchive))
+	say.Say("MOB_SESSION_STALE_AFTER" + "=" + quote(c.SessionStaleAfter))
+	say.Say("MOB_SIGN_COMMITS" + "=" + strconv.FormatBool(c.SignCommits))
+	say.Say("MOB_SIGN_OFF" + "=" + strconv.FormatBool(c.SignOff))
 	say.Say("MOB_SKIP_CI_PUSH_OPTION_ENABLED" + "=" + strconv.FormatBool(c.SkipCiPushOptionEnabled))
 	say.Say("MOB_START_COMMIT_MESSAGE" + "=" + quote(c.StartCommitMessage))
+	say.Say("MOB_START_SYNC" + "=" + strconv.FormatBool(c.StartSync))
 	say.Say("MOB_STASH_NAME" + "=" + quote(c.StashName))
+	say.Say("MOB_SYNC_REBASE" + "=" + strconv.FormatBool(c.SyncRebase))
 	say.Say("MOB_TIMER_INSECURE" + "=" + strconv.FormatBool(c.TimerInsecure))
 	say.Say("MOB_TIMER_LOCAL" + "=" + strconv.FormatBool(c.TimerLocal))
 	say.Say("MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER" + "=" + strconv.FormatBool(c.TimerRoomUseWipBranchQualifier))
@@ -109,69 +206,15 @@ func ReadConfiguration(gitRootDir string) Configuration {
 	configuration := GetDefaultConfiguration()
 	configuration = parseEnvironmentVariables(configuration)
 
-	userHomeDir, _ := os.UserHomeDir()
-	userConfigurationPath := userHomeDir + "/.mob"
-	configuration = parseUserConfiguration(configuration, userConfigurationPath)
+	configuration = parseUserConfiguration(configuration, UserConfigurationPath())
+	configuration = parseGitConfiguration(configuration, gitRootDir)
 	if gitRootDir != "" {
 		configuration = parseProjectConfiguration(configuration, gitRootDir+"/.mob")
 	}
-	return configuration
-}
-
-func ParseArgs(args []string, configuration Configuration) (command string, parameters []string, newConfiguration Configuration) {
-	newConfiguration = configuration
-
-	for i := 1; i < len(args); i++ {
-		arg := args[i]
-		switch arg {
-		case "--discard-uncommitted-changes", "-d":
-			newConfiguration.HandleUncommittedChanges = DiscardChanges
-		case "--include-uncommitted-changes", "-i":
-			newConfiguration.HandleUncommittedChanges = IncludeChanges
-		case "--debug":
-			// ignore this, already parsed
-		case "--stay", "-s":
-			newConfiguration.NextStay = true
-		case "--return-to-base-branch", "-r":
-			newConfiguration.NextStay = false
-		case "--branch", "-b":
-			if i+1 != len(args) {
-				newConfiguration.WipBranchQualifier = args[i+1]
-			}
-			i++ // skip consumed parameter
-		case "--message", "-m":
-			if i+1 != len(args) {
-				newConfiguration.WipCommitMessage = args[i+1]
-			}
-			i++ // skip consumed parameter
-		case "--squash":
-			newConfiguration.DoneSquash = Squash
-		case "--no-squash":
-			newConfiguration.DoneSquash = NoSquash
-		case "--squash-wip":
-			newConfiguration.DoneSquash = SquashWip
-		case "--create", "-c":
-			newConfiguration.StartCreate = true
-		case "--join", "-j":
-			newConfiguration.StartJoin = true
-		case "--delete-remote-wip-branch":
-			newConfiguration.ResetDeleteRemoteWipBranch = true
-		case "--room":
-			if i+1 != len(args) {
-				newConfiguration.TimerRoom = args[i+1]
-			}
-			i++ // skip consumed parameter
-
-		default:
-			if i == 1 {
-				command = arg
-			} else {
-				parameters = append(parameters, arg)
-			}
-		}
+	if configuration.Profile != "" {
+		configuration = applyProfile(configuration, configuration.Profile)
 	}
-
-	return
+	return configuration
 }
 
 func GetDefaultConfiguration() Configuration {
@@ -200,13 +243,25 @@ func GetDefaultConfiguration() Configuration {
 		NotifyCommand:               notifyCommand,
 		NotifyMessage:               "mob next",
 		NextStay:                    true,
+		NextHandover:                false,
 		RequireCommitMessage:        false,
+		SignCommits:                 false,
+		SignOff:                     false,
+		CommitTrailers:              "",
 		HandleUncommittedChanges:    FailWithError,
 		StartCreate:                 false,
+		StartSync:                   false,
+		SyncRebase:                  false,
 		WipBranchQualifier:          "",
 		WipBranchQualifierSeparator: "-",
 		DoneSquash:                  Squash,
+		DonePullRequest:             false,
+		DoneCommitMessageTemplate:   "",
+		DoneTicketPattern:           "[A-Z][A-Z0-9]*-[0-9]+",
+		PullRequestProvider:         "",
+		PullRequestApiUrl:           "",
 		OpenCommand:                 "",
+		OpenFiles:                   "1",
 		Timer:                       "",
 		TimerLocal:                  true,
 		TimerRoom:                   "",
@@ -215,10 +270,18 @@ func GetDefaultConfiguration() Configuration {
 		WipBranchPrefix:             "mob/",
 		StashName:                   "mob-stash-name",
 		ResetDeleteRemoteWipBranch:  false,
+		ResetArchive:                false,
+		LegacyMobSession:            true,
+		SessionStaleAfter:           "14d",
 	}
 }
 
 func parseUserConfiguration(configuration Configuration, path string) Configuration {
+	return parseUserConfigurationProfile(configuration, path, "")
+}
+
+// parses the lines of the given profile, the lines before the first [profile] header belong to no profile ("")
+func parseUserConfigurationProfile(configuration Configuration, path string, profile string) Configuration {
 	file, err := os.Open(path)
 
 	if err != nil {
@@ -227,12 +290,22 @@ func parseUserConfiguration(configuration Configuration, path string) Configurat
 	} else {
 		say.Debug("Found user configuration file at " + path)
 	}
+	defer file.Close()
 
 	fileScanner := bufio.NewScanner(file)
 
+	currentProfile := ""
 	for fileScanner.Scan() {
 		line := strings.TrimSpace(fileScanner.Text())
 		say.Debug(line)
+		if isProfileHeader(line) {
+			currentProfile = profileName(line)
+			continue
+		}
+		if currentProfile != profile {
+			say.Debug("Skip line because it belongs to profile '" + currentProfile + "'. Line=" + line)
+			continue
+		}
 		if !strings.Contains(line, "=") {
 			say.Debug("Skip line because line contains no =. Line=" + line)
 			continue
@@ -241,65 +314,7 @@ func parseUserConfiguration(configuration Configuration, path string) Configurat
 		value := strings.TrimPrefix(line, key+"=")
 		say.Debug("Key is " + key)
 		say.Debug("Value is " + value)
-		switch key {
-		case "MOB_CLI_NAME":
-			setUnquotedString(&configuration.CliName, key, value)
-		case "MOB_REMOTE_NAME":
-			setUnquotedString(&configuration.RemoteName, key, value)
-		case "MOB_WIP_COMMIT_MESSAGE":
-			setUnquotedString(&configuration.WipCommitMessage, key, value)
-		case "MOB_START_COMMIT_MESSAGE":
-			setUnquotedString(&configuration.StartCommitMessage, key, value)
-		case "MOB_SKIP_CI_PUSH_OPTION_ENABLED":
-			setBoolean(&configuration.SkipCiPushOptionEnabled, key, value)
-		case "MOB_GIT_HOOKS_ENABLED":
-			setBoolean(&configuration.GitHooksEnabled, key, value)
-		case "MOB_REQUIRE_COMMIT_MESSAGE":
-			setBoolean(&configuration.RequireCommitMessage, key, value)
-		case "MOB_VOICE_COMMAND":
-			setUnquotedString(&configuration.VoiceCommand, key, value)
-		case "MOB_VOICE_MESSAGE":
-			setUnquotedString(&configuration.VoiceMessage, key, value)
-		case "MOB_NOTIFY_COMMAND":
-			setUnquotedString(&configuration.NotifyCommand, key, value)
-		case "MOB_NOTIFY_MESSAGE":
-			setUnquotedString(&configuration.NotifyMessage, key, value)
-		case "MOB_NEXT_STAY":
-			setBoolean(&configuration.NextStay, key, value)
-		case "MOB_START_CREATE":
-			setBoolean(&configuration.StartCreate, key, value)
-		case "MOB_WIP_BRANCH_QUALIFIER":
-			setUnquotedString(&configuration.WipBranchQualifier, key, value)
-		case "MOB_WIP_BRANCH_QUALIFIER_SEPARATOR":
-			setUnquotedString(&configuration.WipBranchQualifierSeparator, key, value)
-		case "MOB_WIP_BRANCH_PREFIX":
-			setUnquotedString(&configuration.WipBranchPrefix, key, value)
-		case "MOB_DONE_SQUASH":
-			setMobDoneSquash(&configuration, key, value)
-		case "MOB_OPEN_COMMAND":
-			setUnquotedString(&configuration.OpenCommand, key, value)
-		case "MOB_TIMER":
-			setUnquotedString(&configuration.Timer, key, value)
-		case "MOB_TIMER_ROOM":
-			setUnquotedString(&configuration.TimerRoom, key, value)
-		case "MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER":
-			setBoolean(&configuration.TimerRoomUseWipBranchQualifier, key, value)
-		case "MOB_TIMER_LOCAL":
-			setBoolean(&configuration.TimerLocal, key, value)
-		case "MOB_TIMER_USER":
-			setUnquotedString(&configuration.TimerUser, key, value)
-		case "MOB_TIMER_URL":
-			setUnquotedString(&configuration.TimerUrl, key, value)
-		case "MOB_STASH_NAME":
-			setUnquotedString(&configuration.StashName, key, value)
-		case "MOB_TIMER_INSECURE":
-			setBoolean(&configuration.TimerInsecure, key, value)
-		case "MOB_RESET_DELETE_REMOTE_WIP_BRANCH":
-			setBoolean(&configuration.ResetDeleteRemoteWipBranch, key, value)
-
-		default:
-			continue
-		}
+		setConfigurationKey(&configuration, key, value)
 	}
 
 	if err := fileScanner.Err(); err != nil {
@@ -318,12 +333,22 @@ func parseProjectConfiguration(configuration Configuration, path string) Configu
 	} else {
 		say.Debug("Found project configuration file at " + path)
 	}
+	defer file.Close()
 
 	fileScanner := bufio.NewScanner(file)
 
+	currentProfile := ""
 	for fileScanner.Scan() {
 		line := strings.TrimSpace(fileScanner.Text())
 		say.Debug(line)
+		if isProfileHeader(line) {
+			currentProfile = profileName(line)
+			continue
+		}
+		if currentProfile != "" {
+			say.Debug("Skip line because profiles are only supported in the user configuration file. Line=" + line)
+			continue
+		}
 		if !strings.Contains(line, "=") {
 			say.Debug("Skip line because line contains no =. Line=" + line)
 			continue
@@ -332,57 +357,11 @@ func parseProjectConfiguration(configuration Configuration, path string) Configu
 		value := strings.TrimPrefix(line, key+"=")
 		say.Debug("Key is " + key)
 		say.Debug("Value is " + value)
-		switch key {
-		case "MOB_VOICE_COMMAND", "MOB_VOICE_MESSAGE", "MOB_NOTIFY_COMMAND", "MOB_NOTIFY_MESSAGE", "MOB_OPEN_COMMAND":
+		if isProjectRestrictedKey(key) && !isTrustedProjectSetting(&configuration, path, key, value) {
 			say.Warning("Skipped overwriting key " + key + " from project/.mob file out of security reasons!")
-		case "MOB_CLI_NAME":
-			setUnquotedString(&configuration.CliName, key, value)
-		case "MOB_REMOTE_NAME":
-			setUnquotedString(&configuration.RemoteName, key, value)
-		case "MOB_WIP_COMMIT_MESSAGE":
-			setUnquotedString(&configuration.WipCommitMessage, key, value)
-		case "MOB_START_COMMIT_MESSAGE":
-			setUnquotedString(&configuration.StartCommitMessage, key, value)
-		case "MOB_SKIP_CI_PUSH_OPTION_ENABLED":
-			setBoolean(&configuration.SkipCiPushOptionEnabled, key, value)
-		case "MOB_GIT_HOOKS_ENABLED":
-			setBoolean(&configuration.GitHooksEnabled, key, value)
-		case "MOB_REQUIRE_COMMIT_MESSAGE":
-			setBoolean(&configuration.RequireCommitMessage, key, value)
-		case "MOB_NEXT_STAY":
-			setBoolean(&configuration.NextStay, key, value)
-		case "MOB_START_CREATE":
-			setBoolean(&configuration.StartCreate, key, value)
-		case "MOB_WIP_BRANCH_QUALIFIER":
-			setUnquotedString(&configuration.WipBranchQualifier, key, value)
-		case "MOB_WIP_BRANCH_QUALIFIER_SEPARATOR":
-			setUnquotedString(&configuration.WipBranchQualifierSeparator, key, value)
-		case "MOB_WIP_BRANCH_PREFIX":
-			setUnquotedString(&configuration.WipBranchPrefix, key, value)
-		case "MOB_DONE_SQUASH":
-			setMobDoneSquash(&configuration, key, value)
-		case "MOB_TIMER":
-			setUnquotedString(&configuration.Timer, key, value)
-		case "MOB_TIMER_ROOM":
-			setUnquotedString(&configuration.TimerRoom, key, value)
-		case "MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER":
-			setBoolean(&configuration.TimerRoomUseWipBranchQualifier, key, value)
-		case "MOB_TIMER_LOCAL":
-			setBoolean(&configuration.TimerLocal, key, value)
-		case "MOB_TIMER_USER":
-			setUnquotedString(&configuration.TimerUser, key, value)
-		case "MOB_TIMER_URL":
-			setUnquotedString(&configuration.TimerUrl, key, value)
-		case "MOB_STASH_NAME":
-			setUnquotedString(&configuration.StashName, key, value)
-		case "MOB_TIMER_INSECURE":
-			setBoolean(&configuration.TimerInsecure, key, value)
-		case "MOB_RESET_DELETE_REMOTE_WIP_BRANCH":
-			setBoolean(&configuration.ResetDeleteRemoteWipBranch, key, value)
-
-		default:
 			continue
 		}
+		setConfigurationKey(&configuration, key, value)
 	}
 
 	if err := fileScanner.Err(); err != nil {
@@ -392,6 +371,458 @@ func parseProjectConfiguration(configuration Configuration, path string) Configu
 	return configuration
 }
 
+// keys that would allow a repository to execute arbitrary commands on the machine of everyone in the mob
+func isProjectRestrictedKey(key string) bool {
+	switch key {
+	case "MOB_VOICE_COMMAND", "MOB_VOICE_MESSAGE", "MOB_NOTIFY_COMMAND", "MOB_NOTIFY_MESSAGE", "MOB_OPEN_COMMAND", "MOB_TRUSTED_PROJECT_SETTING", "MOB_PULL_REQUEST_API_URL":
+		return true
+	}
+	return false
+}
+
+// trust on first use: the user approves a restricted setting of a project once, until its value changes
+func isTrustedProjectSetting(configuration *Configuration, path string, key string, value string) bool {
+	if key == "MOB_TRUSTED_PROJECT_SETTING" {
+		return false
+	}
+	hash := trustedProjectSettingHash(path, key, value)
+	if stringContains(configuration.TrustedProjectSettings, hash) {
+		say.Debug("Project setting " + key + " is trusted")
+		return true
+	}
+	if !input.IsInteractive() {
+		say.Debug("Cannot ask to trust project setting " + key + ", not running in a terminal")
+		return false
+	}
+	say.Warning("The project configuration file " + path + " sets a command, that will run on your machine:")
+	say.Indented(key + "=" + value)
+	if !input.Confirm("Do you trust this setting?") {
+		return false
+	}
+	if err := addTrustedProjectSetting(UserConfigurationPath(), hash); err != nil {
+		say.Warning("Could not store trusted setting in user configuration file: " + err.Error())
+	}
+	configuration.TrustedProjectSettings = append(configuration.TrustedProjectSettings, hash)
+	return true
+}
+
+// the hash covers the path of the project configuration file, so that an approval does not apply to other repositories
+func trustedProjectSettingHash(path string, key string, value string) string {
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		absolutePath = path
+	}
+	hash := sha256.Sum256([]byte(absolutePath + "\x00" + key + "\x00" + value))
+	return hex.EncodeToString(hash[:])
+}
+
+// inserts the line before the first profile, so that the trust applies to all profiles
+func addTrustedProjectSetting(path string, hash string) error {
+	lines, err := readConfigurationFileLines(path)
+	if err != nil {
+		return err
+	}
+	lines = insertBeforeProfiles(lines, "MOB_TRUSTED_PROJECT_SETTING="+quote(hash))
+	return writeConfigurationFileLines(path, lines)
+}
+
+type Setting struct {
+	Key   string
+	Value string // formatted like in the .mob file, e.g. "\"origin\"" or "true"
+}
+
+func StringSetting(key string, value string) Setting {
+	return Setting{Key: key, Value: quote(value)}
+}
+
+func BoolSetting(key string, value bool) Setting {
+	return Setting{Key: key, Value: strconv.FormatBool(value)}
+}
+
+func ValidateSetting(setting Setting) error {
+	if !stringContains(configurationKeys, setting.Key) {
+		return fmt.Errorf("unknown configuration key %s", setting.Key)
+	}
+	if strings.HasPrefix(setting.Value, "\"") {
+		if _, err := strconv.Unquote(setting.Value); err != nil {
+			return fmt.Errorf("value of %s is not a valid quoted string: %s", setting.Key, setting.Value)
+		}
+		return nil
+	}
+	if setting.Key == "MOB_DONE_SQUASH" {
+		if doneSquash(setting.Value) != setting.Value {
+			return fmt.Errorf("value of %s must be one of %s, %s, %s or %s: %s", setting.Key, Squash, NoSquash, SquashWip, Rebase, setting.Value)
+		}
+		return nil
+	}
+	if _, err := strconv.ParseBool(setting.Value); err != nil {
+		return fmt.Errorf("value of %s must be a quoted string or a boolean: %s", setting.Key, setting.Value)
+	}
+	return nil
+}
+
+// replaces the lines of existing keys outside of profiles and adds the others before the first profile
+func WriteSettings(path string, settings []Setting) error {
+	for _, setting := range settings {
+		if err := ValidateSetting(setting); err != nil {
+			return err
+		}
+	}
+	lines, err := readConfigurationFileLines(path)
+	if err != nil {
+		return err
+	}
+	for _, setting := range settings {
+		line := setting.Key + "=" + setting.Value
+		replaced := false
+		for i := 0; i < len(lines) && !isProfileHeader(strings.TrimSpace(lines[i])); i++ {
+			if strings.HasPrefix(strings.TrimSpace(lines[i]), setting.Key+"=") {
+				lines[i] = line
+				replaced = true
+			}
+		}
+		if !replaced {
+			lines = insertBeforeProfiles(lines, line)
+		}
+	}
+	return writeConfigurationFileLines(path, lines)
+}
+
+// adds the settings whose keys are not set outside of profiles yet, returns the keys of the added settings
+func AddMissingSettings(path string, settings []Setting) ([]string, error) {
+	lines, err := readConfigurationFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+	var missing []Setting
+	var added []string
+	for _, setting := range settings {
+		if !hasSetting(lines, setting.Key) {
+			missing = append(missing, setting)
+			added = append(added, setting.Key)
+		}
+	}
+	if len(missing) == 0 {
+		return added, nil
+	}
+	return added, WriteSettings(path, missing)
+}
+
+func hasSetting(lines []string, key string) bool {
+	for i := 0; i < len(lines) && !isProfileHeader(strings.TrimSpace(lines[i])); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), key+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+func readConfigurationFileLines(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && len(content) == 0) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(string(content), "\n"), "\n"), nil
+}
+
+func writeConfigurationFileLines(path string, lines []string) error {
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func insertBeforeProfiles(lines []string, line string) []string {
+	insertAt := len(lines)
+	for i, existingLine := range lines {
+		if isProfileHeader(strings.TrimSpace(existingLine)) {
+			insertAt = i
+			break
+		}
+	}
+	result := append([]string{}, lines[:insertAt]...)
+	result = append(result, line)
+	return append(result, lines[insertAt:]...)
+}
+
+func stringContains(list []string, element string) bool {
+	for _, entry := range list {
+		if entry == element {
+			return true
+		}
+	}
+	return false
+}
+
+// reads the mob.* section of git config, e.g. mob.remoteName or mob.timerRoom
+func parseGitConfiguration(configuration Configuration, gitRootDir string) Configuration {
+	command := exec.Command("git", "config", "--includes", "--show-origin", "-z", "--get-regexp", `^mob\.`)
+	if len(workdir.Path) > 0 {
+		command.Dir = workdir.Path
+	}
+	outputBytes, err := command.Output()
+	if err != nil {
+		// git config exits with 1 if no key matches
+		say.Debug("No mob configuration found in git config. Error: " + err.Error())
+		return configuration
+	}
+
+	// with -z every entry is "<origin>\x00<name>\n<value>\x00"
+	fields := strings.Split(string(outputBytes), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		origin := fields[i]
+		name, value, hasValue := strings.Cut(fields[i+1], "\n")
+		key := configurationKeyFromGitConfigName(name)
+		say.Debug("Git config " + name + " from " + origin + " is " + value)
+		if key == "" {
+			say.Warning("Skipped unknown git config key " + name + " (" + origin + ")")
+			continue
+		}
+		if isProjectRestrictedKey(key) && isProjectGitConfigOrigin(origin, gitRootDir) {
+			say.Warning("Skipped overwriting key " + name + " from project git config out of security reasons!")
+			continue
+		}
+		if !hasValue && stringContains(booleanConfigurationKeys, key) {
+			// git treats a key without value as true, e.g. "[mob]\n\tnextStay"
+			value = "true"
+		}
+		setConfigurationKey(&configuration, key, quote(value))
+	}
+	return configuration
+}
+
+// git config names are case-insensitive and reported in lower case, e.g. mob.remotename for MOB_REMOTE_NAME
+func configurationKeyFromGitConfigName(name string) string {
+	if !strings.HasPrefix(name, "mob.") {
+		return ""
+	}
+	variable := strings.ToLower(strings.TrimPrefix(name, "mob."))
+	for _, key := range configurationKeys {
+		if strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(key, "MOB_"), "_", "")) == variable {
+			return key
+		}
+	}
+	return ""
+}
+
+// config files inside the repository (like .git/config or an included file that is committed) are treated like the project/.mob file
+func isProjectGitConfigOrigin(origin string, gitRootDir string) bool {
+	if !strings.HasPrefix(origin, "file:") {
+		return false
+	}
+	path := strings.TrimPrefix(origin, "file:")
+	if !filepath.IsAbs(path) {
+		return true
+	}
+	if gitRootDir == "" {
+		return false
+	}
+	relativePath, err := filepath.Rel(gitRootDir, path)
+	return err != nil || !strings.HasPrefix(relativePath, "..")
+}
+
+var configurationKeys = []string{
+	"MOB_CLI_NAME",
+	"MOB_REMOTE_NAME",
+	"MOB_WIP_COMMIT_MESSAGE",
+	"MOB_START_COMMIT_MESSAGE",
+	"MOB_SKIP_CI_PUSH_OPTION_ENABLED",
+	"MOB_GIT_HOOKS_ENABLED",
+	"MOB_REQUIRE_COMMIT_MESSAGE",
+	"MOB_SIGN_COMMITS",
+	"MOB_SIGN_OFF",
+	"MOB_COMMIT_TRAILERS",
+	"MOB_VOICE_COMMAND",
+	"MOB_VOICE_MESSAGE",
+	"MOB_NOTIFY_COMMAND",
+	"MOB_NOTIFY_MESSAGE",
+	"MOB_NEXT_STAY",
+	"MOB_NEXT_HANDOVER",
+	"MOB_START_CREATE",
+	"MOB_START_SYNC",
+	"MOB_SYNC_REBASE",
+	"MOB_WIP_BRANCH_QUALIFIER",
+	"MOB_WIP_BRANCH_QUALIFIER_SEPARATOR",
+	"MOB_WIP_BRANCH_PREFIX",
+	"MOB_DONE_SQUASH",
+	"MOB_DONE_PULL_REQUEST",
+	"MOB_DONE_COMMIT_MESSAGE_TEMPLATE",
+	"MOB_DONE_TICKET_PATTERN",
+	"MOB_PULL_REQUEST_PROVIDER",
+	"MOB_PULL_REQUEST_API_URL",
+	"MOB_OPEN_COMMAND",
+	"MOB_OPEN_FILES",
+	"MOB_TIMER",
+	"MOB_TIMER_ROOM",
+	"MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER",
+	"MOB_TIMER_LOCAL",
+	"MOB_TIMER_USER",
+	"MOB_TIMER_URL",
+	"MOB_STASH_NAME",
+	"MOB_TIMER_INSECURE",
+	"MOB_RESET_DELETE_REMOTE_WIP_BRANCH",
+	"MOB_RESET_ARCHIVE",
+	"MOB_LEGACY_MOB_SESSION",
+	"MOB_SESSION_STALE_AFTER",
+}
+
+var booleanConfigurationKeys = []string{
+	"MOB_SKIP_CI_PUSH_OPTION_ENABLED",
+	"MOB_GIT_HOOKS_ENABLED",
+	"MOB_REQUIRE_COMMIT_MESSAGE",
+	"MOB_SIGN_COMMITS",
+	"MOB_SIGN_OFF",
+	"MOB_NEXT_STAY",
+	"MOB_NEXT_HANDOVER",
+	"MOB_START_CREATE",
+	"MOB_START_SYNC",
+	"MOB_SYNC_REBASE",
+	"MOB_DONE_PULL_REQUEST",
+	"MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER",
+	"MOB_TIMER_LOCAL",
+	"MOB_TIMER_INSECURE",
+	"MOB_RESET_DELETE_REMOTE_WIP_BRANCH",
+	"MOB_RESET_ARCHIVE",
+	"MOB_LEGACY_MOB_SESSION",
+}
+
+func setConfigurationKey(configuration *Configuration, key string, value string) {
+	switch key {
+	case "MOB_CLI_NAME":
+		setUnquotedString(&configuration.CliName, key, value)
+	case "MOB_REMOTE_NAME":
+		setUnquotedString(&configuration.RemoteName, key, value)
+	case "MOB_WIP_COMMIT_MESSAGE":
+		setUnquotedString(&configuration.WipCommitMessage, key, value)
+	case "MOB_START_COMMIT_MESSAGE":
+		setUnquotedString(&configuration.StartCommitMessage, key, value)
+	case "MOB_SKIP_CI_PUSH_OPTION_ENABLED":
+		setBoolean(&configuration.SkipCiPushOptionEnabled, key, value)
+	case "MOB_GIT_HOOKS_ENABLED":
+		setBoolean(&configuration.GitHooksEnabled, key, value)
+	case "MOB_REQUIRE_COMMIT_MESSAGE":
+		setBoolean(&configuration.RequireCommitMessage, key, value)
+	case "MOB_SIGN_COMMITS":
+		setBoolean(&configuration.SignCommits, key, value)
+	case "MOB_SIGN_OFF":
+		setBoolean(&configuration.SignOff, key, value)
+	case "MOB_COMMIT_TRAILERS":
+		setUnquotedString(&configuration.CommitTrailers, key, value)
+	case "MOB_VOICE_COMMAND":
+		setUnquotedString(&configuration.VoiceCommand, key, value)
+	case "MOB_VOICE_MESSAGE":
+		setUnquotedString(&configuration.VoiceMessage, key, value)
+	case "MOB_NOTIFY_COMMAND":
+		setUnquotedString(&configuration.NotifyCommand, key, value)
+	case "MOB_NOTIFY_MESSAGE":
+		setUnquotedString(&configuration.NotifyMessage, key, value)
+	case "MOB_NEXT_STAY":
+		setBoolean(&configuration.NextStay, key, value)
+	case "MOB_NEXT_HANDOVER":
+		setBoolean(&configuration.NextHandover, key, value)
+	case "MOB_START_CREATE":
+		setBoolean(&configuration.StartCreate, key, value)
+	case "MOB_START_SYNC":
+		setBoolean(&configuration.StartSync, key, value)
+	case "MOB_SYNC_REBASE":
+		setBoolean(&configuration.SyncRebase, key, value)
+	case "MOB_WIP_BRANCH_QUALIFIER":
+		setUnquotedString(&configuration.WipBranchQualifier, key, value)
+	case "MOB_WIP_BRANCH_QUALIFIER_SEPARATOR":
+		setUnquotedString(&configuration.WipBranchQualifierSeparator, key, value)
+	case "MOB_WIP_BRANCH_PREFIX":
+		setUnquotedString(&configuration.WipBranchPrefix, key, value)
+	case "MOB_DONE_SQUASH":
+		setMobDoneSquash(configuration, key, value)
+	case "MOB_DONE_PULL_REQUEST":
+		setBoolean(&configuration.DonePullRequest, key, value)
+	case "MOB_DONE_COMMIT_MESSAGE_TEMPLATE":
+		setUnquotedString(&configuration.DoneCommitMessageTemplate, key, value)
+	case "MOB_DONE_TICKET_PATTERN":
+		setUnquotedString(&configuration.DoneTicketPattern, key, value)
+	case "MOB_PULL_REQUEST_PROVIDER":
+		setUnquotedString(&configuration.PullRequestProvider, key, value)
+	case "MOB_PULL_REQUEST_API_URL":
+		setUnquotedString(&configuration.PullRequestApiUrl, key, value)
+	case "MOB_OPEN_COMMAND":
+		setUnquotedString(&configuration.OpenCommand, key, value)
+	case "MOB_OPEN_FILES":
+		setUnquotedString(&configuration.OpenFiles, key, value)
+	case "MOB_TIMER":
+		setUnquotedString(&configuration.Timer, key, value)
+	case "MOB_TIMER_ROOM":
+		setUnquotedString(&configuration.TimerRoom, key, value)
+	case "MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER":
+		setBoolean(&configuration.TimerRoomUseWipBranchQualifier, key, value)
+	case "MOB_TIMER_LOCAL":
+		setBoolean(&configuration.TimerLocal, key, value)
+	case "MOB_TIMER_USER":
+		setUnquotedString(&configuration.TimerUser, key, value)
+	case "MOB_TIMER_URL":
+		setUnquotedString(&configuration.TimerUrl, key, value)
+	case "MOB_STASH_NAME":
+		setUnquotedString(&configuration.StashName, key, value)
+	case "MOB_TIMER_INSECURE":
+		setBoolean(&configuration.TimerInsecure, key, value)
+	case "MOB_RESET_DELETE_REMOTE_WIP_BRANCH":
+		setBoolean(&configuration.ResetDeleteRemoteWipBranch, key, value)
+	case "MOB_RESET_ARCHIVE":
+		setBoolean(&configuration.ResetArchive, key, value)
+	case "MOB_LEGACY_MOB_SESSION":
+		setBoolean(&configuration.LegacyMobSession, key, value)
+	case "MOB_SESSION_STALE_AFTER":
+		setUnquotedString(&configuration.SessionStaleAfter, key, value)
+	case "MOB_TRUSTED_PROJECT_SETTING":
+		var hash string
+		setUnquotedString(&hash, key, value)
+		if hash != "" {
+			configuration.TrustedProjectSettings = append(configuration.TrustedProjectSettings, hash)
+		}
+	}
+}
+
+func isProfileHeader(line string) bool {
+	return strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]")
+}
+
+func profileName(line string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"))
+}
+
+func hasProfile(path string, profile string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	fileScanner := bufio.NewScanner(file)
+	for fileScanner.Scan() {
+		line := strings.TrimSpace(fileScanner.Text())
+		if isProfileHeader(line) && profileName(line) == profile {
+			return true
+		}
+	}
+	return false
+}
+
+func applyProfile(configuration Configuration, profile string) Configuration {
+	path := UserConfigurationPath()
+	if !hasProfile(path, profile) {
+		say.Warning("Profile '" + profile + "' not found in user configuration file (" + path + ")")
+		return configuration
+	}
+	say.Debug("Applying profile '" + profile + "'")
+	configuration = parseUserConfigurationProfile(configuration, path, profile)
+	configuration.Profile = profile
+	return configuration
+}
+
+func UserConfigurationPath() string {
+	userHomeDir, _ := os.UserHomeDir()
+	return userHomeDir + "/.mob"
+}
+
 func setUnquotedString(s *string, key string, value string) {
 	unquotedValue, err := strconv.Unquote(value)
 	if err != nil {
@@ -403,6 +834,11 @@ func setUnquotedString(s *string, key string, value string) {
 }
 
 func setBoolean(s *bool, key string, value string) {
+	if strings.HasPrefix(value, "\"") {
+		if unquotedValue, err := strconv.Unquote(value); err == nil {
+			value = unquotedValue
+		}
+	}
 	boolValue, err := strconv.ParseBool(value)
 	if err != nil {
 		say.Warning("Could not set key from configuration file because value is not parseable (" + key + "=" + value + ")")
@@ -445,6 +881,9 @@ func parseEnvironmentVariables(configuration Configuration) Configuration {
 	setBoolFromEnvVariable(&configuration.SkipCiPushOptionEnabled, "MOB_SKIP_CI_PUSH_OPTION_ENABLED")
 	setBoolFromEnvVariable(&configuration.GitHooksEnabled, "MOB_GIT_HOOKS_ENABLED")
 	setBoolFromEnvVariable(&configuration.RequireCommitMessage, "MOB_REQUIRE_COMMIT_MESSAGE")
+	setBoolFromEnvVariable(&configuration.SignCommits, "MOB_SIGN_COMMITS")
+	setBoolFromEnvVariable(&configuration.SignOff, "MOB_SIGN_OFF")
+	setStringFromEnvVariable(&configuration.CommitTrailers, "MOB_COMMIT_TRAILERS")
 	setOptionalStringFromEnvVariable(&configuration.VoiceCommand, "MOB_VOICE_COMMAND")
 	setStringFromEnvVariable(&configuration.VoiceMessage, "MOB_VOICE_MESSAGE")
 	setOptionalStringFromEnvVariable(&configuration.NotifyCommand, "MOB_NOTIFY_COMMAND")
@@ -455,12 +894,21 @@ func parseEnvironmentVariables(configuration Configuration) Configuration {
 	setStringFromEnvVariable(&configuration.WipBranchPrefix, "MOB_WIP_BRANCH_PREFIX")
 
 	setBoolFromEnvVariable(&configuration.NextStay, "MOB_NEXT_STAY")
+	setBoolFromEnvVariable(&configuration.NextHandover, "MOB_NEXT_HANDOVER")
 
 	setBoolFromEnvVariable(&configuration.StartCreate, "MOB_START_CREATE")
+	setBoolFromEnvVariable(&configuration.StartSync, "MOB_START_SYNC")
+	setBoolFromEnvVariable(&configuration.SyncRebase, "MOB_SYNC_REBASE")
 
 	setDoneSquashFromEnvVariable(&configuration, "MOB_DONE_SQUASH")
+	setBoolFromEnvVariable(&configuration.DonePullRequest, "MOB_DONE_PULL_REQUEST")
+	setStringFromEnvVariable(&configuration.DoneCommitMessageTemplate, "MOB_DONE_COMMIT_MESSAGE_TEMPLATE")
+	setStringFromEnvVariable(&configuration.DoneTicketPattern, "MOB_DONE_TICKET_PATTERN")
+	setStringFromEnvVariable(&configuration.PullRequestProvider, "MOB_PULL_REQUEST_PROVIDER")
+	setStringFromEnvVariable(&configuration.PullRequestApiUrl, "MOB_PULL_REQUEST_API_URL")
 
 	setStringFromEnvVariable(&configuration.OpenCommand, "MOB_OPEN_COMMAND")
+	setStringFromEnvVariable(&configuration.OpenFiles, "MOB_OPEN_FILES")
 
 	setStringFromEnvVariable(&configuration.Timer, "MOB_TIMER")
 	setStringFromEnvVariable(&configuration.TimerRoom, "MOB_TIMER_ROOM")
@@ -471,6 +919,11 @@ func parseEnvironmentVariables(configuration Configuration) Configuration {
 	setBoolFromEnvVariable(&configuration.TimerInsecure, "MOB_TIMER_INSECURE")
 
 	setBoolFromEnvVariable(&configuration.ResetDeleteRemoteWipBranch, "MOB_RESET_DELETE_REMOTE_WIP_BRANCH")
+	setBoolFromEnvVariable(&configuration.ResetArchive, "MOB_RESET_ARCHIVE")
+	setBoolFromEnvVariable(&configuration.LegacyMobSession, "MOB_LEGACY_MOB_SESSION")
+	setStringFromEnvVariable(&configuration.SessionStaleAfter, "MOB_SESSION_STALE_AFTER")
+
+	setStringFromEnvVariable(&configuration.Profile, "MOB_PROFILE")
 
 	return configuration
 }
@@ -553,6 +1006,8 @@ func doneSquash(value string) string {
 		return NoSquash
 	case SquashWip:
 		return SquashWip
+	case Rebase:
+		return Rebase
 	default:
 		return Squash
 	}
@@ -2,11 +2,14 @@ package configuration
 
 import (
 	"fmt"
+	"github.com/remotemobprogramming/mob/v5/input"
 	"github.com/remotemobprogramming/mob/v5/say"
 	"github.com/remotemobprogramming/mob/v5/test"
 	"os"
+	"os/exec"
 	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -22,8 +25,9 @@ func TestParseArgs(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 	test.Equals(t, configuration.WipBranchQualifier, "")
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start", "--branch", "green"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "--branch", "green"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, "", strings.Join(parameters, ""))
 	test.Equals(t, "green", configuration.WipBranchQualifier)
@@ -32,8 +36,9 @@ func TestParseArgs(t *testing.T) {
 func TestParseArgsStartCreate(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start", "--create"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "--create"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, "", strings.Join(parameters, ""))
 	test.Equals(t, true, configuration.StartCreate)
@@ -42,8 +47,9 @@ func TestParseArgsStartCreate(t *testing.T) {
 func TestParseArgsStartCreateShort(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start", "-c"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "-c"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, "", strings.Join(parameters, ""))
 	test.Equals(t, true, configuration.StartCreate)
@@ -52,8 +58,9 @@ func TestParseArgsStartCreateShort(t *testing.T) {
 func TestParseArgsStartJoin(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start", "--join"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "--join"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, "", strings.Join(parameters, ""))
 	test.Equals(t, true, configuration.StartJoin)
@@ -62,19 +69,44 @@ func TestParseArgsStartJoin(t *testing.T) {
 func TestParseArgsStartJoinShort(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start", "-j"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "-j"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, "", strings.Join(parameters, ""))
 	test.Equals(t, true, configuration.StartJoin)
 }
 
+func TestParseArgsStartStack(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "--stack", "--branch", "experiment"}, configuration)
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "start", command)
+	test.Equals(t, "", strings.Join(parameters, ""))
+	test.Equals(t, true, configuration.StartStack)
+	test.Equals(t, "experiment", configuration.WipBranchQualifier)
+}
+
+func TestParseArgsNextInteractive(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "next", "--interactive"}, configuration)
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "next", command)
+	test.Equals(t, "", strings.Join(parameters, ""))
+	test.Equals(t, true, configuration.NextInteractive)
+}
+
 func TestParseArgsDoneNoSquash(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 	test.Equals(t, Squash, configuration.DoneSquash)
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "done", "--no-squash"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "done", "--no-squash"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "done", command)
 	test.Equals(t, "", strings.Join(parameters, ""))
 	test.Equals(t, NoSquash, configuration.DoneSquash)
@@ -84,8 +116,9 @@ func TestParseArgsDoneSquash(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 	configuration.DoneSquash = NoSquash
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "done", "--squash"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "done", "--squash"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "done", command)
 	test.Equals(t, "", strings.Join(parameters, ""))
 	test.Equals(t, Squash, configuration.DoneSquash)
@@ -95,8 +128,9 @@ func TestParseArgsMessage(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 	test.Equals(t, configuration.WipBranchQualifier, "")
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "next", "--message", "ci-skip"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "next", "--message", "ci-skip"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "next", command)
 	test.Equals(t, "", strings.Join(parameters, ""))
 	test.Equals(t, "ci-skip", configuration.WipCommitMessage)
@@ -106,8 +140,9 @@ func TestParseArgsStartRoom(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 	test.Equals(t, configuration.WipBranchQualifier, "")
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start", "--room", "testroom"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "--room", "testroom"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, "", strings.Join(parameters, ""))
 	test.Equals(t, "testroom", configuration.TimerRoom)
@@ -116,8 +151,9 @@ func TestParseArgsStartRoom(t *testing.T) {
 func TestDefaultConfigurationHandleUncommitedChanges(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, 0, len(parameters))
 	test.Equals(t, FailWithError, configuration.HandleUncommittedChanges)
@@ -126,8 +162,9 @@ func TestDefaultConfigurationHandleUncommitedChanges(t *testing.T) {
 func TestParseArgsIncludeUncommitedChanges(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start", "--include-uncommitted-changes"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "--include-uncommitted-changes"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, 0, len(parameters))
 	test.Equals(t, IncludeChanges, configuration.HandleUncommittedChanges)
@@ -136,8 +173,9 @@ func TestParseArgsIncludeUncommitedChanges(t *testing.T) {
 func TestParseArgsIncludeUncommitedChangesShort(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start", "-i"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "-i"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, 0, len(parameters))
 	test.Equals(t, IncludeChanges, configuration.HandleUncommittedChanges)
@@ -146,8 +184,9 @@ func TestParseArgsIncludeUncommitedChangesShort(t *testing.T) {
 func TestParseArgsDiscardUncommitedChanges(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start", "--discard-uncommitted-changes"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "--discard-uncommitted-changes"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, 0, len(parameters))
 	test.Equals(t, DiscardChanges, configuration.HandleUncommittedChanges)
@@ -156,8 +195,9 @@ func TestParseArgsDiscardUncommitedChanges(t *testing.T) {
 func TestParseArgsDiscardUncommitedChangesShort(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "start", "-d"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "-d"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "start", command)
 	test.Equals(t, 0, len(parameters))
 	test.Equals(t, DiscardChanges, configuration.HandleUncommittedChanges)
@@ -167,8 +207,9 @@ func TestParseArgsTimerRoom(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 	test.Equals(t, configuration.WipBranchQualifier, "")
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "timer", "10", "--room", "testroom"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "timer", "10", "--room", "testroom"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "timer", command)
 	test.Equals(t, "10", strings.Join(parameters, ""))
 	test.Equals(t, "testroom", configuration.TimerRoom)
@@ -178,8 +219,9 @@ func TestParseArgsTimerOpenRoom(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 	test.Equals(t, configuration.WipBranchQualifier, "")
 
-	command, parameters, configuration := ParseArgs([]string{"mob", "timer", "open", "--room", "testroom"}, configuration)
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "timer", "open", "--room", "testroom"}, configuration)
 
+	test.Equals(t, nil, err)
 	test.Equals(t, "timer", command)
 	test.Equals(t, "open", strings.Join(parameters, ""))
 	test.Equals(t, "testroom", configuration.TimerRoom)
@@ -414,6 +456,68 @@ func TestReadConfigurationFromFileWithNonBooleanQuotedDoneSquashValue(t *testing
 	test.Equals(t, SquashWip, actualConfiguration.DoneSquash)
 }
 
+func TestReadConfigurationFromFileWithMultilineCommitMessageTemplate(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+
+	test.CreateFile(t, ".mob", "MOB_DONE_COMMIT_MESSAGE_TEMPLATE=\"{{.Ticket}}\\n\\n{{.Duration}}\"\nMOB_DONE_TICKET_PATTERN=\"#[0-9]+\"")
+	actualConfiguration := parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+	test.Equals(t, "{{.Ticket}}\n\n{{.Duration}}", actualConfiguration.DoneCommitMessageTemplate)
+	test.Equals(t, "#[0-9]+", actualConfiguration.DoneTicketPattern)
+}
+
+func TestReadConfigurationFromFileWithCommitSigningAndTrailers(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+
+	test.CreateFile(t, ".mob", "MOB_SIGN_COMMITS=true\nMOB_SIGN_OFF=true\nMOB_COMMIT_TRAILERS=\"Team: green\\nRefs: #42\\n\"")
+	actualConfiguration := parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+	test.Equals(t, true, actualConfiguration.SignCommits)
+	test.Equals(t, true, actualConfiguration.SignOff)
+	test.Equals(t, []string{"Team: green", "Refs: #42"}, actualConfiguration.CommitTrailerLines())
+}
+
+func TestOpenFiles(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+	test.Equals(t, 1, configuration.OpenFilesLimit())
+	test.Equals(t, false, configuration.OpenAllFiles())
+
+	configuration.OpenFiles = "3"
+	test.Equals(t, 3, configuration.OpenFilesLimit())
+
+	configuration.OpenFiles = "none"
+	test.Equals(t, 1, configuration.OpenFilesLimit())
+
+	configuration.OpenFiles = "all"
+	test.Equals(t, true, configuration.OpenAllFiles())
+}
+
+func TestParseAge(t *testing.T) {
+	age, err := ParseAge("30d")
+	test.Equals(t, nil, err)
+	test.Equals(t, 30*24*time.Hour, age)
+
+	age, _ = ParseAge("2w")
+	test.Equals(t, 14*24*time.Hour, age)
+	age, _ = ParseAge("12h")
+	test.Equals(t, 12*time.Hour, age)
+	age, _ = ParseAge("90m")
+	test.Equals(t, 90*time.Minute, age)
+
+	for _, invalid := range []string{"", "d", "30", "30y", "-1d", "thirty days"} {
+		_, err = ParseAge(invalid)
+		test.NotEquals(t, nil, err)
+	}
+}
+
+func TestSessionStaleAfterDuration(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+	test.Equals(t, 14*24*time.Hour, configuration.SessionStaleAfterDuration())
+
+	configuration.SessionStaleAfter = "forever"
+	test.Equals(t, 14*24*time.Hour, configuration.SessionStaleAfterDuration())
+}
+
 func TestReadConfigurationFromFileAndSkipBrokenLines(t *testing.T) {
 	say.TurnOnDebugging()
 	tempDir = t.TempDir()
@@ -462,3 +566,366 @@ func TestSetMobDoneSquashEmptyStringValue(t *testing.T) {
 	setMobDoneSquash(&configuration, "", "")
 	test.Equals(t, Squash, configuration.DoneSquash)
 }
+
+func TestReadUserConfigurationProfile(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+
+	test.CreateFile(t, ".mob", `
+		MOB_TIMER_ROOM="default-room"
+		MOB_TIMER_LOCAL=false
+
+		[workshop]
+		MOB_TIMER_LOCAL=true
+		MOB_TIMER="5"
+
+		[team]
+		MOB_TIMER_ROOM="team-room"
+		MOB_DONE_SQUASH=squash-wip
+	`)
+	actualConfiguration := parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+	test.Equals(t, "default-room", actualConfiguration.TimerRoom)
+	test.Equals(t, false, actualConfiguration.TimerLocal)
+	test.Equals(t, "", actualConfiguration.Timer)
+	test.Equals(t, Squash, actualConfiguration.DoneSquash)
+
+	workshopConfiguration := parseUserConfigurationProfile(actualConfiguration, tempDir+"/.mob", "workshop")
+	test.Equals(t, "default-room", workshopConfiguration.TimerRoom)
+	test.Equals(t, true, workshopConfiguration.TimerLocal)
+	test.Equals(t, "5", workshopConfiguration.Timer)
+
+	teamConfiguration := parseUserConfigurationProfile(actualConfiguration, tempDir+"/.mob", "team")
+	test.Equals(t, "team-room", teamConfiguration.TimerRoom)
+	test.Equals(t, false, teamConfiguration.TimerLocal)
+	test.Equals(t, SquashWip, teamConfiguration.DoneSquash)
+}
+
+func TestReadProjectConfigurationIgnoresProfiles(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+
+	test.CreateFile(t, ".mob", `
+		MOB_TIMER_ROOM="project-room"
+		[team]
+		MOB_TIMER_ROOM="team-room"
+	`)
+	actualConfiguration := parseProjectConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+	test.Equals(t, "project-room", actualConfiguration.TimerRoom)
+}
+
+func TestParseArgsProfile(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+	t.Setenv("HOME", tempDir)
+	test.CreateFile(t, ".mob", `
+		[workshop]
+		MOB_TIMER="5"
+		MOB_TIMER_ROOM="workshop-room"
+	`)
+
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "--room", "other-room", "--profile", "workshop"}, GetDefaultConfiguration())
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "start", command)
+	test.Equals(t, 0, len(parameters))
+	test.Equals(t, "workshop", configuration.Profile)
+	test.Equals(t, "5", configuration.Timer)
+	test.Equals(t, "other-room", configuration.TimerRoom)
+}
+
+func TestParseArgsUnknownProfile(t *testing.T) {
+	output := test.CaptureOutput(t)
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+	t.Setenv("HOME", tempDir)
+	test.CreateFile(t, ".mob", "MOB_TIMER=\"10\"")
+
+	_, _, configuration, _ := ParseArgs([]string{"mob", "config", "--profile", "unknown"}, GetDefaultConfiguration())
+
+	test.Equals(t, "", configuration.Profile)
+	test.AssertOutputContains(t, output, "Profile 'unknown' not found in user configuration file")
+}
+
+func TestReadConfigurationWithProfileEnvironmentVariable(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+	t.Setenv("HOME", tempDir)
+	t.Setenv("MOB_PROFILE", "team")
+	test.CreateFile(t, ".mob", `
+		MOB_TIMER_ROOM="default-room"
+		[team]
+		MOB_TIMER_ROOM="team-room"
+	`)
+
+	configuration := ReadConfiguration("")
+
+	test.Equals(t, "team", configuration.Profile)
+	test.Equals(t, "team-room", configuration.TimerRoom)
+}
+
+func TestReadGitConfiguration(t *testing.T) {
+	output := test.CaptureOutput(t)
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	gitInTempDir(t, "init")
+	gitInTempDir(t, "config", "--global", "mob.timerRoom", "global-room")
+	gitInTempDir(t, "config", "--global", "mob.openCommand", "idea %s")
+	gitInTempDir(t, "config", "mob.remoteName", "gitlab")
+	gitInTempDir(t, "config", "mob.doneSquash", "squash-wip")
+	gitInTempDir(t, "config", "mob.nextStay", "false")
+	gitInTempDir(t, "config", "mob.voiceCommand", "rm -rf %s")
+
+	configuration := parseGitConfiguration(GetDefaultConfiguration(), tempDir)
+
+	test.Equals(t, "global-room", configuration.TimerRoom)
+	test.Equals(t, "idea %s", configuration.OpenCommand)
+	test.Equals(t, "gitlab", configuration.RemoteName)
+	test.Equals(t, SquashWip, configuration.DoneSquash)
+	test.Equals(t, false, configuration.NextStay)
+	test.Equals(t, GetDefaultConfiguration().VoiceCommand, configuration.VoiceCommand)
+	test.AssertOutputContains(t, output, "Skipped overwriting key mob.voicecommand from project git config out of security reasons!")
+}
+
+func TestReadGitConfigurationWithBooleanKeyWithoutValue(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	gitInTempDir(t, "init")
+	gitConfig, _ := os.OpenFile(tempDir+"/.git/config", os.O_APPEND|os.O_WRONLY, 0644)
+	gitConfig.WriteString("[mob]\n\tnextStay\n\tstartCreate\n")
+	gitConfig.Close()
+
+	configuration := parseGitConfiguration(GetDefaultConfiguration(), tempDir)
+
+	test.Equals(t, true, configuration.NextStay)
+	test.Equals(t, true, configuration.StartCreate)
+}
+
+func TestReadGitConfigurationOutsideOfGitRepository(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+	t.Setenv("HOME", tempDir)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	configuration := parseGitConfiguration(GetDefaultConfiguration(), "")
+
+	test.Equals(t, GetDefaultConfiguration(), configuration)
+}
+
+func TestConfigurationKeyFromGitConfigName(t *testing.T) {
+	test.Equals(t, "MOB_REMOTE_NAME", configurationKeyFromGitConfigName("mob.remotename"))
+	test.Equals(t, "MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER", configurationKeyFromGitConfigName("mob.timerRoomUseWipBranchQualifier"))
+	test.Equals(t, "MOB_TIMER", configurationKeyFromGitConfigName("mob.timer"))
+	test.Equals(t, "", configurationKeyFromGitConfigName("mob.unknown"))
+	test.Equals(t, "", configurationKeyFromGitConfigName("user.name"))
+}
+
+func TestIsProjectGitConfigOrigin(t *testing.T) {
+	test.Equals(t, true, isProjectGitConfigOrigin("file:.git/config", "/repo"))
+	test.Equals(t, true, isProjectGitConfigOrigin("file:/repo/team.gitconfig", "/repo"))
+	test.Equals(t, false, isProjectGitConfigOrigin("file:/home/user/.gitconfig", "/repo"))
+	test.Equals(t, false, isProjectGitConfigOrigin("file:/repository/.gitconfig", "/repo"))
+	test.Equals(t, false, isProjectGitConfigOrigin("command line:", "/repo"))
+}
+
+func gitInTempDir(t *testing.T, args ...string) {
+	command := exec.Command("git", args...)
+	command.Dir = tempDir
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %s %s", strings.Join(args, " "), err.Error(), output)
+	}
+}
+
+func TestReadProjectConfigurationTrustOnFirstUse(t *testing.T) {
+	output := test.CaptureOutput(t)
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+	userHomeDir := t.TempDir()
+	t.Setenv("HOME", userHomeDir)
+	mockInput(t, "y\n")
+	test.CreateFile(t, ".mob", "MOB_OPEN_COMMAND=\"idea %s\"\n")
+
+	configuration := parseProjectConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+
+	test.Equals(t, "idea %s", configuration.OpenCommand)
+	test.AssertOutputContains(t, output, "Do you trust this setting?")
+	userConfiguration := parseUserConfiguration(GetDefaultConfiguration(), userHomeDir+"/.mob")
+	test.Equals(t, 1, len(userConfiguration.TrustedProjectSettings))
+
+	// approved settings are applied without asking again
+	mockInput(t, "")
+	configuration = parseProjectConfiguration(userConfiguration, tempDir+"/.mob")
+	test.Equals(t, "idea %s", configuration.OpenCommand)
+
+	// changed values need to be approved again
+	test.CreateFile(t, ".mob", "MOB_OPEN_COMMAND=\"evil %s\"\n")
+	configuration = parseProjectConfiguration(userConfiguration, tempDir+"/.mob")
+	test.Equals(t, "", configuration.OpenCommand)
+	test.AssertOutputContains(t, output, "Skipped overwriting key MOB_OPEN_COMMAND from project/.mob file out of security reasons!")
+}
+
+func TestReadProjectConfigurationTrustDeclined(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+	userHomeDir := t.TempDir()
+	t.Setenv("HOME", userHomeDir)
+	mockInput(t, "n\n")
+	test.CreateFile(t, ".mob", "MOB_OPEN_COMMAND=\"idea %s\"\n")
+
+	configuration := parseProjectConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+
+	test.Equals(t, "", configuration.OpenCommand)
+	_, err := os.Stat(userHomeDir + "/.mob")
+	test.Equals(t, true, os.IsNotExist(err))
+}
+
+func TestReadProjectConfigurationCannotTrustItself(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+	test.CreateFile(t, ".mob", "MOB_OPEN_COMMAND=\"idea %s\"\nMOB_TRUSTED_PROJECT_SETTING="+quote(trustedProjectSettingHash(tempDir+"/.mob", "MOB_OPEN_COMMAND", "\"idea %s\""))+"\n")
+
+	configuration := parseProjectConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+
+	test.Equals(t, "", configuration.OpenCommand)
+	test.Equals(t, 0, len(configuration.TrustedProjectSettings))
+}
+
+func TestAddTrustedProjectSettingBeforeProfiles(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+	test.CreateFile(t, ".mob", "MOB_TIMER=\"10\"\n[workshop]\nMOB_TIMER=\"5\"\n")
+
+	err := addTrustedProjectSetting(tempDir+"/.mob", "abc")
+
+	test.Equals(t, nil, err)
+	content, _ := os.ReadFile(tempDir + "/.mob")
+	test.Equals(t, "MOB_TIMER=\"10\"\nMOB_TRUSTED_PROJECT_SETTING=\"abc\"\n[workshop]\nMOB_TIMER=\"5\"\n", string(content))
+}
+
+func mockInput(t *testing.T, text string) {
+	originalIn, originalIsInteractive := input.In, input.IsInteractive
+	input.In = strings.NewReader(text)
+	input.IsInteractive = func() bool { return true }
+	t.Cleanup(func() {
+		input.In, input.IsInteractive = originalIn, originalIsInteractive
+	})
+}
+
+func TestParseArgsOptionWithEqualsSign(t *testing.T) {
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "--branch=green", "--room=testroom"}, GetDefaultConfiguration())
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "start", command)
+	test.Equals(t, 0, len(parameters))
+	test.Equals(t, "green", configuration.WipBranchQualifier)
+	test.Equals(t, "testroom", configuration.TimerRoom)
+}
+
+func TestParseArgsCombinedShortOptions(t *testing.T) {
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "start", "10", "-icb", "green"}, GetDefaultConfiguration())
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "start", command)
+	test.Equals(t, []string{"10"}, parameters)
+	test.Equals(t, IncludeChanges, configuration.HandleUncommittedChanges)
+	test.Equals(t, true, configuration.StartCreate)
+	test.Equals(t, "green", configuration.WipBranchQualifier)
+}
+
+func TestParseArgsShortOptionWithAttachedValue(t *testing.T) {
+	_, _, configuration, err := ParseArgs([]string{"mob", "next", "-mmy message"}, GetDefaultConfiguration())
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "my message", configuration.WipCommitMessage)
+}
+
+func TestParseArgsTerminator(t *testing.T) {
+	command, parameters, configuration, err := ParseArgs([]string{"mob", "goal", "--", "--squash", "the", "bugs"}, GetDefaultConfiguration())
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "goal", command)
+	test.Equals(t, []string{"--squash", "the", "bugs"}, parameters)
+	test.Equals(t, Squash, configuration.DoneSquash)
+}
+
+func TestParseArgsPassesCommandOptionsAsParameters(t *testing.T) {
+	command, parameters, _, err := ParseArgs([]string{"mob", "g", "--delete"}, GetDefaultConfiguration())
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "g", command)
+	test.Equals(t, []string{"--delete"}, parameters)
+
+	command, parameters, _, err = ParseArgs([]string{"mob", "init", "--remote=upstream", "-y"}, GetDefaultConfiguration())
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "init", command)
+	test.Equals(t, []string{"--remote", "upstream", "--yes"}, parameters)
+}
+
+func TestParseArgsHelpAndVersion(t *testing.T) {
+	command, parameters, _, err := ParseArgs([]string{"mob", "start", "--help"}, GetDefaultConfiguration())
+	test.Equals(t, nil, err)
+	test.Equals(t, "start", command)
+	test.Equals(t, []string{"--help"}, parameters)
+
+	command, _, _, err = ParseArgs([]string{"mob", "--version"}, GetDefaultConfiguration())
+	test.Equals(t, nil, err)
+	test.Equals(t, "--version", command)
+
+	command, _, _, err = ParseArgs([]string{"mob", "--debug", "status"}, GetDefaultConfiguration())
+	test.Equals(t, nil, err)
+	test.Equals(t, "status", command)
+}
+
+func TestParseArgsMissingValue(t *testing.T) {
+	_, _, _, err := ParseArgs([]string{"mob", "start", "--branch"}, GetDefaultConfiguration())
+	test.Equals(t, "option '--branch' requires a value", err.Error())
+
+	_, _, _, err = ParseArgs([]string{"mob", "start", "-b"}, GetDefaultConfiguration())
+	test.Equals(t, "option '-b' requires a value", err.Error())
+}
+
+func TestParseArgsValueForOptionWithoutValue(t *testing.T) {
+	_, _, _, err := ParseArgs([]string{"mob", "start", "--create=true"}, GetDefaultConfiguration())
+
+	test.Equals(t, "option '--create' does not take a value", err.Error())
+}
+
+func TestParseArgsUnknownOptionWithSuggestion(t *testing.T) {
+	_, _, _, err := ParseArgs([]string{"mob", "done", "--sqash"}, GetDefaultConfiguration())
+
+	test.Equals(t, "unknown option '--sqash' for 'mob done', did you mean '--squash'?", err.Error())
+}
+
+func TestParseArgsUnknownOptionWithoutSuggestion(t *testing.T) {
+	_, _, _, err := ParseArgs([]string{"mob", "start", "-x"}, GetDefaultConfiguration())
+
+	test.Equals(t, "unknown option '-x' for 'mob start'", err.Error())
+}
+
+func TestParseArgsMisplacedOption(t *testing.T) {
+	_, _, _, err := ParseArgs([]string{"mob", "next", "--squash"}, GetDefaultConfiguration())
+	test.Equals(t, "option '--squash' is not supported by 'mob next', it is supported by: done, init", err.Error())
+
+	_, _, _, err = ParseArgs([]string{"mob", "--branch", "green", "start"}, GetDefaultConfiguration())
+	test.Equals(t, "option '--branch' must be given after the command, it is supported by: migrate, reset, start", err.Error())
+}
+
+func TestParseArgsUnknownCommandIsNotValidated(t *testing.T) {
+	command, parameters, _, err := ParseArgs([]string{"mob", "whatever", "--unknown"}, GetDefaultConfiguration())
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "whatever", command)
+	test.Equals(t, []string{"--unknown"}, parameters)
+}
+
+func TestIsOptionGiven(t *testing.T) {
+	test.Equals(t, true, IsOptionGiven([]string{"mob", "start", "--branch", "green"}, "branch"))
+	test.Equals(t, true, IsOptionGiven([]string{"mob", "start", "--branch=green"}, "branch"))
+	test.Equals(t, true, IsOptionGiven([]string{"mob", "start", "-cb", "green"}, "branch"))
+	test.Equals(t, false, IsOptionGiven([]string{"mob", "start", "-c"}, "branch"))
+	test.Equals(t, false, IsOptionGiven([]string{"mob", "start", "--", "--branch"}, "branch"))
+}
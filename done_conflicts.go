@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/exit"
+	mobgit "github.com/remotemobprogramming/mob/v5/git"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// remembers the wip branch of a 'mob done' that stopped because of merge conflicts, as we are on the base branch then,
+// followed by the commits before 'mob done' (one per line, see doneState), which 'mob done --abort' restores
+const doneWipBranchFile = "mob-done-wip-branch"
+
+// the state before 'mob done' made its wip commit and merged the wip branch, empty commits are unknown
+type doneState struct {
+	wipBranch      Branch
+	wipHead        string
+	remoteWipHead  string
+	baseBranchHead string
+}
+
+func stateBeforeDone(configuration config.Configuration, baseBranch Branch, wipBranch Branch) doneState {
+	state := doneState{wipBranch: wipBranch, wipHead: silentgit("rev-parse", "HEAD")}
+	state.remoteWipHead, _ = silentgitignorefailure("rev-parse", "--verify", "--quiet", wipBranch.remote(configuration).Name)
+	state.baseBranchHead, _ = silentgitignorefailure("rev-parse", "--verify", "--quiet", baseBranch.Name)
+	return state
+}
+
+func (s doneState) String() string {
+	return strings.Join([]string{s.wipBranch.Name, s.wipHead, s.remoteWipHead, s.baseBranchHead}, "\n") + "\n"
+}
+
+func sayDoneMergeConflicts(configuration config.Configuration, state doneState) {
+	if err := os.WriteFile(gitDir()+"/"+doneWipBranchFile, []byte(state.String()), 0644); err != nil {
+		say.Warning("could not remember the wip branch: " + err.Error())
+	}
+	say.Warning("Merging " + state.wipBranch.Name + " stopped because of conflicts in:")
+	sayConflictedFiles()
+	say.Fix("To continue, solve the conflicts, stage them with 'git add' and use", configuration.Mob("done --continue"))
+	say.Fix("To abort and return to the wip branch, use", configuration.Mob("done --abort"))
+}
+
+func sayConflictedFiles() {
+	for _, file := range getConflictedFiles() {
+		say.WithPrefix(file, "  - ")
+	}
+}
+
+func isDoneInProgress(configuration config.Configuration) bool {
+	_, mergeInProgress := doneMergeState()
+	return mergeInProgress || isDoneRebaseInProgress(configuration)
+}
+
+func doneMergeState() (doneState, bool) {
+	content, err := os.ReadFile(gitDir() + "/" + doneWipBranchFile)
+	if err != nil {
+		return doneState{}, false
+	}
+	lines := append(strings.Split(strings.TrimSpace(string(content)), "\n"), "", "", "")
+	return doneState{
+		wipBranch:      newBranch(lines[0]),
+		wipHead:        strings.TrimSpace(lines[1]),
+		remoteWipHead:  strings.TrimSpace(lines[2]),
+		baseBranchHead: strings.TrimSpace(lines[3]),
+	}, true
+}
+
+func sayDoneInProgress(configuration config.Configuration) {
+	say.Warning("'" + configuration.Mob("done") + "' stopped because of conflicts in:")
+	sayConflictedFiles()
+	say.Fix("To continue, solve the conflicts, stage them with 'git add' and use", configuration.Mob("done --continue"))
+	say.Fix("To abort and return to the wip branch, use", configuration.Mob("done --abort"))
+}
+
+func doneContinue(configuration config.Configuration) {
+	if isDoneRebaseInProgress(configuration) {
+		continueDoneRebase(configuration)
+		return
+	}
+	state, inProgress := doneMergeState()
+	if !inProgress {
+		say.Error("There is no '" + configuration.Mob("done") + "' to continue.")
+		exit.Exit(1)
+		return
+	}
+	if len(getConflictedFiles()) > 0 {
+		sayDoneMergeConflicts(configuration, state)
+		return
+	}
+	wipBranch := state.wipBranch
+
+	if isMergeInProgress() {
+		appendFinalCommitTrailers(configuration, "MERGE_MSG", collectCoauthors("HEAD..MERGE_HEAD"))
+		gitWithoutEmptyStrings("commit", "--no-edit", "--cleanup=strip", gitHooksOption(configuration), mobgit.SignOption(configuration))
+	}
+	removeDoneWipBranchFile()
+	baseBranch, _ := determineBranches(wipBranch, gitBranches(), configuration)
+	finishDone(configuration, baseBranch, wipBranch, collectSessionCoauthors(configuration, baseBranch, wipBranch))
+}
+
+func doneAbort(configuration config.Configuration) {
+	if isDoneRebaseInProgress(configuration) {
+		wipBranch := rebaseHeadBranch()
+		git("rebase", "--abort")
+		forgetDoneRebaseWipCommit()
+		say.Info("aborted '" + configuration.Mob("done") + "', you are back on wip branch '" + wipBranch.Name + "'")
+		return
+	}
+	state, inProgress := doneMergeState()
+	if !inProgress {
+		say.Error("There is no '" + configuration.Mob("done") + "' to abort.")
+		exit.Exit(1)
+		return
+	}
+
+	git("reset", "--hard", "HEAD") // also removes the state of a merge or squash merge
+	if state.baseBranchHead != "" {
+		git("reset", "--hard", state.baseBranchHead) // undoes the fast-forward to the remote base branch
+	}
+	removeDoneWipBranchFile()
+	git("checkout", state.wipBranch.Name)
+	restoreWipBranch(configuration, state)
+	say.Info("aborted '" + configuration.Mob("done") + "', you are back on wip branch '" + state.wipBranch.Name + "'")
+}
+
+// undoes the wip commit and the rewritten history of 'mob done' on the wip branch, locally and on the remote
+func restoreWipBranch(configuration config.Configuration, state doneState) {
+	wipBranch := state.wipBranch
+	remoteWipHead, _ := silentgitignorefailure("rev-parse", "--verify", "--quiet", wipBranch.remote(configuration).Name)
+	if state.remoteWipHead != "" && remoteWipHead != "" && state.remoteWipHead != remoteWipHead {
+		pushArgs := []string{"push", "--force-with-lease=" + wipBranch.Name + ":" + remoteWipHead, configuration.RemoteName, state.remoteWipHead + ":refs/heads/" + wipBranch.Name}
+		if gitHooksOption(configuration) != "" {
+			pushArgs = append(pushArgs, gitHooksOption(configuration))
+		}
+		if err := gitIgnoreFailure(pushArgs...); err != nil {
+			say.Warning("Could not restore " + wipBranch.remote(configuration).Name + ", because someone else pushed to it.")
+		}
+	}
+	if state.wipHead != "" && state.wipHead != silentgit("rev-parse", "HEAD") {
+		// the tree stays untouched, so the changes of the wip commit are uncommitted again
+		git("reset", "--quiet", state.wipHead)
+	}
+}
+
+func removeDoneWipBranchFile() {
+	if err := os.Remove(gitDir() + "/" + doneWipBranchFile); err != nil {
+		say.Debug("could not remove " + doneWipBranchFile + ": " + err.Error())
+	}
+}
+
+func isMergeInProgress() bool {
+	_, err := os.Stat(gitDir() + "/MERGE_HEAD")
+	return err == nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func createDoneMergeConflict(t *testing.T, configuration config.Configuration) {
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "example.txt", "alice")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "localother", "conflicting commit")
+	git("push")
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	done(configuration)
+}
+
+func TestDoneMergeConflictListsConflictedFiles(t *testing.T) {
+	output, configuration := setup(t)
+
+	createDoneMergeConflict(t, configuration)
+
+	assertOnBranch(t, "master")
+	assertOutputContains(t, output, "Merging mob-session stopped because of conflicts in:")
+	assertOutputContains(t, output, "  - example.txt")
+	assertOutputContains(t, output, "mob done --continue")
+	assertOutputContains(t, output, "mob done --abort")
+}
+
+func TestDoneMergeConflictContinueWithSquash(t *testing.T) {
+	output, configuration := setup(t)
+	createDoneMergeConflict(t, configuration)
+
+	doneContinue(configuration)
+	assertOutputContains(t, output, "Merging mob-session stopped because of conflicts in:")
+	assertLocalBranch(t, "mob-session")
+
+	createFile(t, "example.txt", "resolved")
+	git("add", "example.txt")
+	doneContinue(configuration)
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "M",
+	})
+	squashMsg := readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG"))
+	assertOutputContains(t, &squashMsg, "Co-authored-by: alice <alice@example.com>")
+	assertOutputContains(t, output, "git commit")
+}
+
+func TestDoneMergeConflictContinueWithNoSquash(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.NoSquash
+	createDoneMergeConflict(t, configuration)
+
+	createFile(t, "example.txt", "resolved")
+	git("add", "example.txt")
+	doneContinue(configuration)
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	assertGitStatus(t, GitStatus{})
+	message := silentgit("log", "-1", "--format=%B")
+	assertOutputContains(t, &message, "Merge branch 'mob-session'")
+	assertOutputContains(t, &message, "\n\nCo-authored-by: alice <alice@example.com>")
+	assertOutputNotContains(t, &message, "# Conflicts")
+}
+
+func TestDoneMergeConflictAbort(t *testing.T) {
+	output, configuration := setup(t)
+	createDoneMergeConflict(t, configuration)
+
+	doneAbort(configuration)
+
+	assertOnBranch(t, "mob-session")
+	assertGitStatus(t, GitStatus{})
+	equals(t, "alice", readFile(t, tempDir+"/local/example.txt"))
+	assertMobSessionBranches(t, configuration, "mob-session")
+	assertOutputContains(t, output, "aborted 'mob done', you are back on wip branch 'mob-session'")
+
+	start(configuration)
+	assertOnBranch(t, "mob-session")
+}
+
+func TestDoneMergeConflictAbortRestoresStateBeforeDone(t *testing.T) {
+	_, configuration := setup(t)
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "example.txt", "alice")
+	next(configuration)
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "localother", "conflicting commit")
+	git("push")
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	wipHead, remoteWipHead, baseBranchHead := silentgit("rev-parse", "HEAD"), silentgit("rev-parse", "origin/mob-session"), silentgit("rev-parse", "master")
+	createFile(t, "local.txt", "contentIrrelevant")
+	done(configuration)
+
+	doneAbort(configuration)
+
+	assertOnBranch(t, "mob-session")
+	assertGitStatus(t, GitStatus{"local.txt": "??"})
+	equals(t, wipHead, silentgit("rev-parse", "HEAD"))
+	equals(t, remoteWipHead, silentgit("rev-parse", "origin/mob-session"))
+	equals(t, baseBranchHead, silentgit("rev-parse", "master"))
+	equals(t, false, isDoneInProgress(configuration))
+}
+
+func TestDoneWhileConflictsAreUnresolved(t *testing.T) {
+	output, configuration := setup(t)
+	createDoneMergeConflict(t, configuration)
+
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertOutputContains(t, output, "'mob done' stopped because of conflicts in:")
+}
+
+func TestDoneContinueWithoutDone(t *testing.T) {
+	output, configuration := setup(t)
+	mockExit()
+	defer resetExit()
+
+	doneContinue(configuration)
+
+	assertOutputContains(t, output, "There is no 'mob done' to continue.")
+}
+
+func TestDoneContinueViaCommandLine(t *testing.T) {
+	output, configuration := setup(t)
+	createDoneMergeConflict(t, configuration)
+	createFile(t, "example.txt", "resolved")
+	git("add", "example.txt")
+
+	execute("done", []string{"--continue"}, configuration)
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	assertOutputContains(t, output, "git commit")
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/remotemobprogramming/mob/v5/coauthors"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// DoneCommitMessageData is available in MOB_DONE_COMMIT_MESSAGE_TEMPLATE, e.g. {{.Ticket}} or {{range .Commits}}
+type DoneCommitMessageData struct {
+	BaseBranch string
+	WipBranch  string
+	Commits    []string // subjects of the manual commits, oldest first
+	Coauthors  []string
+	Duration   string // time since the first commit of the session, e.g. 1h25m
+	Ticket     string // found with MOB_DONE_TICKET_PATTERN in the wip branch or base branch
+}
+
+// renders MOB_DONE_COMMIT_MESSAGE_TEMPLATE for the commits of the wip branch, returns false if no template is configured
+func doneCommitMessage(configuration config.Configuration, baseBranch Branch, wipBranch Branch, sessionCoauthors []coauthors.Author) (string, bool) {
+	if configuration.DoneCommitMessageTemplate == "" {
+		return "", false
+	}
+	message, err := renderDoneCommitMessage(configuration.DoneCommitMessageTemplate, collectDoneCommitMessageData(configuration, baseBranch, wipBranch, sessionCoauthors))
+	if err != nil {
+		say.Warning("Could not use MOB_DONE_COMMIT_MESSAGE_TEMPLATE: " + err.Error())
+		return "", false
+	}
+	return message, true
+}
+
+func collectDoneCommitMessageData(configuration config.Configuration, baseBranch Branch, wipBranch Branch, sessionCoauthors []coauthors.Author) DoneCommitMessageData {
+	commits := baseBranch.remote(configuration).Name + ".." + wipBranch.Name
+	return DoneCommitMessageData{
+		BaseBranch: baseBranch.Name,
+		WipBranch:  wipBranch.Name,
+		Commits:    manualCommitSubjects(configuration, commits),
+		Coauthors:  sessionCoauthors,
+		Duration:   sessionDuration(commits, time.Now()),
+		Ticket:     ticketId(configuration.DoneTicketPattern, wipBranch, baseBranch),
+	}
+}
+
+func renderDoneCommitMessage(messageTemplate string, data DoneCommitMessageData) (string, error) {
+	parsed, err := template.New("done").Parse(messageTemplate)
+	if err != nil {
+		return "", err
+	}
+	var message bytes.Buffer
+	if err := parsed.Execute(&message, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(message.String()) + "\n", nil
+}
+
+func ticketId(pattern string, branches ...Branch) string {
+	if pattern == "" {
+		return ""
+	}
+	matcher, err := regexp.Compile(pattern)
+	if err != nil {
+		say.Warning("Could not use MOB_DONE_TICKET_PATTERN: " + err.Error())
+		return ""
+	}
+	for _, branch := range branches {
+		if ticket := matcher.FindString(branch.Name); ticket != "" {
+			return ticket
+		}
+	}
+	return ""
+}
+
+func sessionDuration(commits string, now time.Time) string {
+	timestamps := strings.Split(silentgit("log", "--reverse", "--pretty=format:%at", commits), "\n")
+	firstCommit, err := strconv.ParseInt(timestamps[0], 10, 64)
+	if err != nil {
+		return ""
+	}
+	return formatDuration(now.Sub(time.Unix(firstCommit, 0)))
+}
+
+func formatDuration(duration time.Duration) string {
+	if duration < time.Minute {
+		return "0m"
+	}
+	return strings.TrimSuffix(duration.Truncate(time.Minute).String(), "0s")
+}
+
+// git commit uses the SQUASH_MSG file to prefill the commit message
+func prefillCommitMessage(message string) {
+	if err := os.WriteFile(gitDir()+"/SQUASH_MSG", []byte(message), 0644); err != nil {
+		say.Warning(err.Error())
+	}
+}
+
+// subjects of the commits that are neither wip nor start commits, oldest first
+func manualCommitSubjects(configuration config.Configuration, commits string) []string {
+	var subjects []string
+	for _, subject := range strings.Split(silentgit("log", "--reverse", "--pretty=format:%s", commits), "\n") {
+		if subject == "" || configuration.IsWipCommitMessage(subject) || strings.HasPrefix(subject, configuration.StartCommitMessage) {
+			continue
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects
+}
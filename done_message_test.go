@@ -0,0 +1,123 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+const exampleDoneCommitMessageTemplate = `{{.Ticket}}: {{range $i, $c := .Commits}}{{if $i}}, {{end}}{{$c}}{{end}}
+
+Mob session on {{.BaseBranch}} ({{.WipBranch}}) took {{.Duration}}.
+{{range .Coauthors}}
+Co-authored-by: {{.}}{{end}}`
+
+func TestRenderDoneCommitMessage(t *testing.T) {
+	message, err := renderDoneCommitMessage(exampleDoneCommitMessageTemplate, DoneCommitMessageData{
+		BaseBranch: "feature/PROJ-42-login",
+		WipBranch:  "mob/feature/PROJ-42-login",
+		Commits:    []string{"add login form", "validate password"},
+		Coauthors:  []string{"alice <alice@example.com>", "bob <bob@example.com>"},
+		Duration:   "1h25m",
+		Ticket:     "PROJ-42",
+	})
+
+	equals(t, nil, err)
+	equals(t, `PROJ-42: add login form, validate password
+
+Mob session on feature/PROJ-42-login (mob/feature/PROJ-42-login) took 1h25m.
+
+Co-authored-by: alice <alice@example.com>
+Co-authored-by: bob <bob@example.com>
+`, message)
+}
+
+func TestRenderDoneCommitMessageWithInvalidTemplate(t *testing.T) {
+	_, err := renderDoneCommitMessage("{{.Unknown}}", DoneCommitMessageData{})
+
+	equals(t, false, err == nil)
+}
+
+func TestTicketId(t *testing.T) {
+	pattern := config.GetDefaultConfiguration().DoneTicketPattern
+
+	equals(t, "PROJ-42", ticketId(pattern, newBranch("mob/main-PROJ-42"), newBranch("main")))
+	equals(t, "AB1-7", ticketId(pattern, newBranch("mob-session"), newBranch("feature/AB1-7-login")))
+	equals(t, "", ticketId(pattern, newBranch("mob/main"), newBranch("main")))
+	equals(t, "123", ticketId("[0-9]+", newBranch("mob/issue-123")))
+}
+
+func TestFormatDuration(t *testing.T) {
+	equals(t, "0m", formatDuration(30*time.Second))
+	equals(t, "25m", formatDuration(25*time.Minute+10*time.Second))
+	equals(t, "1h25m", formatDuration(85*time.Minute))
+}
+
+func TestDoneSquashWithCommitMessageTemplate(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.DoneCommitMessageTemplate = "{{.Ticket}} {{range .Commits}}{{.}}{{end}}\n{{range .Coauthors}}\nCo-authored-by: {{.}}{{end}}"
+	configuration.WipBranchQualifier = "PROJ-42"
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "alice.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFileAndCommitIt(t, "local.txt", "contentIrrelevant", "add greeting")
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	equals(t, "PROJ-42 add greeting\n\nCo-authored-by: alice <alice@example.com>\n", readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG")))
+	assertOutputContains(t, output, "git commit")
+
+	git("commit", "--no-edit")
+	equals(t, "PROJ-42 add greeting\n\nCo-authored-by: alice <alice@example.com>", lastCommitMessage())
+}
+
+func TestDoneSquashWipWithCommitMessageTemplate(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.SquashWip
+	configuration.DoneCommitMessageTemplate = "finish {{.BaseBranch}} after {{range .Commits}}{{.}}{{end}}"
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFileAndCommitIt(t, "local.txt", "contentIrrelevant", "add greeting")
+	createFile(t, "wip.txt", "contentIrrelevant")
+	done(configuration)
+
+	assertGitStatus(t, GitStatus{
+		"wip.txt": "A",
+	})
+	equals(t, "finish master after add greeting\n", readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG")))
+}
+
+func TestDoneNoSquashEndingOnManualCommitWarnsAboutCommitMessageTemplate(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.DoneSquash = config.NoSquash
+	configuration.DoneCommitMessageTemplate = "finish {{.WipBranch}}"
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFileAndCommitIt(t, "local.txt", "contentIrrelevant", "add greeting")
+	done(configuration)
+
+	assertOutputContains(t, output, "MOB_DONE_COMMIT_MESSAGE_TEMPLATE is not used, because the session ended with the commit 'add greeting' and there is no final commit to write.")
+	equals(t, "add greeting", lastCommitMessage())
+}
+
+func TestDoneRebaseWithCommitMessageTemplate(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.Rebase
+	configuration.DoneCommitMessageTemplate = "finish {{.WipBranch}}"
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "wip.txt", "contentIrrelevant")
+	done(configuration)
+
+	equals(t, "finish mob-session\n", readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG")))
+}
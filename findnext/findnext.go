@@ -1,5 +1,17 @@
 package findnext
 
+import "github.com/remotemobprogramming/mob/v5/coauthors"
+
+// CanonicalNames resolves committers "Full Name <email>" with the registry and returns their names,
+// so that someone committing from several machines is counted as one person.
+func CanonicalNames(committers []string, registry coauthors.Registry) []string {
+	names := make([]string, len(committers))
+	for i, committer := range committers {
+		names[i] = coauthors.Name(registry.Canonical(committer))
+	}
+	return names
+}
+
 // FindNextTypist determines who should type next based on the commit history.
 // lastCommitters is the list of recent committers (most recent first).
 // gitUserName is the current git user's name.
@@ -1,8 +1,10 @@
 package findnext
 
 import (
+	"os"
 	"testing"
 
+	"github.com/remotemobprogramming/mob/v5/coauthors"
 	"github.com/remotemobprogramming/mob/v5/test"
 )
 
@@ -122,3 +124,14 @@ func TestFindNextTypistIgnoreCommitterBeforeLastCommitOutsideThreshold(t *testin
 	test.Equals(t, nextTypist, "craig")
 	test.Equals(t, history, []string{"craig", "bob", "alice"})
 }
+
+func TestCanonicalNamesMergesAliasesOfTheSamePerson(t *testing.T) {
+	path := t.TempDir() + "/.mob-authors"
+	test.Equals(t, nil, os.WriteFile(path, []byte("ab, alice-laptop = Alice Bauer <alice@example.com>\n"), 0644))
+	registry, err := coauthors.ReadRegistry(path)
+	test.Equals(t, nil, err)
+
+	names := CanonicalNames([]string{"alice-laptop <alice@laptop.local>", "bob <bob@example.com>", "alice <alice@example.com>"}, registry)
+
+	test.Equals(t, []string{"Alice Bauer", "bob", "Alice Bauer"}, names)
+}
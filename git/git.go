@@ -2,6 +2,7 @@ package git
 
 import (
 	"bufio"
+	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -162,6 +163,40 @@ func HooksOption(c config.Configuration) string {
 	}
 }
 
+// SignOption signs commits with the signing key configured in git (user.signingkey, gpg.format)
+func SignOption(c config.Configuration) string {
+	if c.SignCommits {
+		return "--gpg-sign"
+	}
+	return ""
+}
+
+func SignOffOption(c config.Configuration) string {
+	if c.SignOff {
+		return "--signoff"
+	}
+	return ""
+}
+
+// AppendTrailers adds the trailers ("Key: value") to the commit message in messageFile, e.g. .git/SQUASH_MSG, creating
+// the file if it does not exist yet. Trailers that are already in the message are not added twice.
+func (g *Client) AppendTrailers(messageFile string, trailers []string) error {
+	if len(trailers) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(messageFile); os.IsNotExist(err) {
+		if err := os.WriteFile(messageFile, []byte{}, 0644); err != nil {
+			return err
+		}
+	}
+	args := []string{"interpret-trailers", "--in-place", "--no-divider", "--if-exists", "addIfDifferent"}
+	for _, trailer := range trailers {
+		args = append(args, "--trailer", trailer)
+	}
+	_, err := g.SilentIgnoreFailure(append(args, messageFile)...)
+	return err
+}
+
 func (g *Client) CurrentBranch() string {
 	// upgrade to branch --show-current when git v2.21 is more widely spread
 	return g.Silent("rev-parse", "--abbrev-ref", "HEAD")
@@ -25,29 +25,27 @@ type PutGoalRequest struct {
 	User string `json:"user"`
 }
 
-func Goal(configuration config.Configuration, parameter []string) {
-	if err := goal(configuration, parameter); err != nil {
+// Goal shows, sets or deletes the goal of the timer room and returns the current goal, so that it can be remembered
+func Goal(configuration config.Configuration, parameter []string) (string, error) {
+	currentGoal, err := goal(configuration, parameter)
+	if err != nil {
 		say.Error(err.Error())
 		exit(1)
 	}
+	return currentGoal, err
 }
 
-func goal(configuration config.Configuration, parameter []string) error {
+func goal(configuration config.Configuration, parameter []string) (string, error) {
 	if configuration.TimerRoom == "" {
-		return errors.New("No room specified. Set MOB_TIMER_ROOM to your timer.mob.sh room in .mob file.")
+		return "", errors.New("No room specified. Set MOB_TIMER_ROOM to your timer.mob.sh room in .mob file.")
 	}
-	var err error
 	if len(parameter) <= 0 {
-		err = showGoal(configuration)
+		return showGoal(configuration)
 	} else if parameter[0] == "--delete" {
-		err = deleteCurrentGoal(configuration)
-	} else {
-		err = setNewGoal(configuration, strings.Join(parameter, " "))
-	}
-	if err != nil {
-		return err
+		return "", deleteCurrentGoal(configuration)
 	}
-	return nil
+	newGoal := strings.Join(parameter, " ")
+	return newGoal, setNewGoal(configuration, newGoal)
 }
 
 func setNewGoal(configuration config.Configuration, goal string) error {
@@ -93,18 +91,18 @@ func deleteGoalHttp(room string, user string, timerService string, disableSslVer
 	return err
 }
 
-func showGoal(configuration config.Configuration) error {
+func showGoal(configuration config.Configuration) (string, error) {
 	goal, err := getGoalHttp(configuration.TimerRoom, configuration.TimerUrl, configuration.TimerInsecure)
 	if err != nil {
 		say.Debug(err.Error())
-		return errors.New("Could not get goal. An error occurred while sending the request.")
+		return "", errors.New("Could not get goal. An error occurred while sending the request.")
 	}
 	if goal == "" {
 		say.Fix("No goal set. To set a goal, use", configuration.Mob("goal <your awesome goal>"))
-		return nil
+		return "", nil
 	}
 	say.Info(goal)
-	return nil
+	return goal, nil
 }
 func getGoalHttp(room string, timerService string, disableSslVerification bool) (string, error) {
 	url := timerService + room + "/goal"
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// 'mob next --handover' pushes the uncommitted changes, including untracked files, as a stash commit to this ref
+// instead of making a wip commit, 'mob start' applies it to the working tree and deletes it
+const handoverRefPrefix = "refs/mob/handover/"
+
+func handoverRef(wipBranch Branch) string {
+	return handoverRefPrefix + wipBranch.Name
+}
+
+func nextHandover(configuration config.Configuration, wipBranch Branch, excluded []string) {
+	if wipBranch.hasLocalCommits(configuration) {
+		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, wipBranch.Name)
+	}
+
+	stashChangesToHandOver("mob handover of "+wipBranch.Name, excluded)
+	handover := silentgit("rev-parse", "stash@{0}")
+	pushArgs := []string{"push", "--force", configuration.RemoteName, handover + ":" + handoverRef(wipBranch)}
+	if gitHooksOption(configuration) != "" {
+		pushArgs = append(pushArgs, gitHooksOption(configuration))
+	}
+	if err := gitIgnoreFailure(pushArgs...); err != nil {
+		git("stash", "pop")
+		say.Error("Could not hand over your uncommitted changes, they are still in your working tree.")
+		say.Fix("To hand them over with a wip commit instead, use", configuration.Mob("next --no-handover"))
+		return
+	}
+	git("stash", "drop")
+	say.Info("handed over your uncommitted changes as " + handoverRef(wipBranch) + ", '" + wipBranch.String() + "' is untouched")
+}
+
+// applies the handed over changes to the working tree and deletes the handover, if it is based on the current commit
+func restoreHandover(configuration config.Configuration, wipBranch Branch) {
+	ref := handoverRef(wipBranch)
+	output, err := silentgitignorefailure("ls-remote", configuration.RemoteName, ref)
+	if err != nil || output == "" {
+		return
+	}
+	handover, _, _ := strings.Cut(output, "\t")
+	restoreCommand := "git fetch " + configuration.RemoteName + " " + ref + " && git stash apply FETCH_HEAD"
+
+	if hasUncommittedChanges() {
+		say.Warning("There are handed over changes in " + ref + ", but your working tree is not clean.")
+		say.Fix("To restore them anyway, use", restoreCommand)
+		return
+	}
+	git("fetch", configuration.RemoteName, ref)
+	if base := silentgit("rev-parse", handover+"^1"); base != silentgit("rev-parse", "HEAD") {
+		say.Warning("The handed over changes in " + ref + " are based on " + shortHash(base) + ", which is not the last commit of '" + wipBranch.String() + "'.")
+		say.Fix("To restore them anyway, use", restoreCommand)
+		return
+	}
+	if err := gitIgnoreFailure("stash", "apply", handover); err != nil {
+		say.Warning("Could not restore the handed over changes in " + ref + ".")
+		say.Fix("To restore them yourself, use", restoreCommand)
+		return
+	}
+	deleteHandover(configuration, wipBranch)
+	say.Info("restored the handed over changes from " + ref)
+}
+
+func deleteHandover(configuration config.Configuration, wipBranch Branch) {
+	if _, err := silentgitignorefailure("push", configuration.RemoteName, "--delete", handoverRef(wipBranch)); err != nil {
+		say.Debug("no handover to delete: " + err.Error())
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/workdir"
+)
+
+func TestNextHandoverLeavesWipBranchUntouched(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	wipCommit := silentgit("rev-parse", "HEAD")
+	createFile(t, "new.txt", "contentIrrelevant")
+	configuration.NextHandover = true
+
+	next(configuration)
+
+	assertOutputContains(t, output, "handed over your uncommitted changes as refs/mob/handover/mob-session, 'mob-session' is untouched")
+	equals(t, wipCommit, silentgit("rev-parse", "origin/mob-session"))
+	equals(t, wipCommit, silentgit("rev-parse", "mob-session"))
+	equals(t, true, silentgit("ls-remote", "origin", "refs/mob/handover/mob-session") != "")
+	equals(t, "", silentgit("stash", "list"))
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{})
+}
+
+func TestStartRestoresHandover(t *testing.T) {
+	output, configuration := setup(t)
+	handoverModifiedAndNewFile(t, configuration)
+	setWorkingDir(tempDir + "/alice")
+
+	start(configuration)
+
+	assertOutputContains(t, output, "restored the handed over changes from refs/mob/handover/mob-session")
+	assertOnBranch(t, "mob-session")
+	assertGitStatus(t, GitStatus{"tracked.txt": "M", "new.txt": "??"})
+	equals(t, "v2", readFile(t, filepath.Join(workdir.Path, "tracked.txt")))
+	equals(t, "", silentgit("ls-remote", "origin", "refs/mob/handover/mob-session"))
+}
+
+func TestStartKeepsHandoverBasedOnOtherCommit(t *testing.T) {
+	output, configuration := setup(t)
+	handoverModifiedAndNewFile(t, configuration)
+	setWorkingDir(tempDir + "/alice")
+	git("fetch", "origin")
+	git("checkout", "-b", "mob-session", "origin/mob-session")
+	createFileAndCommitIt(t, "other.txt", "contentIrrelevant", "other commit")
+	git("push", "origin", "mob-session")
+
+	restoreHandover(configuration, newBranch("mob-session"))
+
+	assertOutputContains(t, output, "The handed over changes in refs/mob/handover/mob-session are based on ")
+	assertOutputContains(t, output, "git fetch origin refs/mob/handover/mob-session && git stash apply FETCH_HEAD")
+	assertGitStatus(t, GitStatus{})
+	equals(t, true, silentgit("ls-remote", "origin", "refs/mob/handover/mob-session") != "")
+}
+
+func TestDoneRestoresHandover(t *testing.T) {
+	output, configuration := setup(t)
+	handoverModifiedAndNewFile(t, configuration)
+	setWorkingDir(tempDir + "/alice")
+	git("fetch", "origin")
+	git("checkout", "-b", "mob-session", "origin/mob-session")
+
+	done(configuration)
+
+	assertOutputContains(t, output, "restored the handed over changes from refs/mob/handover/mob-session")
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{"tracked.txt": "A", "new.txt": "A"})
+}
+
+func TestResetDeletesHandover(t *testing.T) {
+	_, configuration := setup(t)
+	handoverModifiedAndNewFile(t, configuration)
+	configuration.ResetDeleteRemoteWipBranch = true
+
+	reset(configuration)
+
+	equals(t, "", silentgit("ls-remote", "origin", "refs/mob/handover/mob-session"))
+}
+
+// hands over a modified tracked file and a new file in 'mob-session' from local, who returns to 'master'
+func handoverModifiedAndNewFile(t *testing.T, configuration config.Configuration) {
+	start(configuration)
+	createFileAndCommitIt(t, "tracked.txt", "v1", "tracked file")
+	git("push", "origin", "mob-session")
+	createFile(t, "tracked.txt", "v2")
+	createFile(t, "new.txt", "contentIrrelevant")
+	configuration.NextHandover = true
+	next(configuration)
+}
@@ -22,17 +22,55 @@ Basic Commands with Options:
     [--branch|-b <branch-postfix>]       Set wip branch to 'mob/<base-branch>` + configuration.WipBranchQualifierSeparator + `<branch-postfix>'
     [--create|-c]                        Create the remote branch
     [--join|-j]                          Join existing wip branch
+    [--stack]                            Start a stacked session on top of the current wip branch
+    [--sync]                             Merge the remote base branch into the wip branch when joining
     [--room <room-name>]                 Set room name for timer.mob.sh once
   next
     [--stay|-s]                          Stay on wip branch (default)
     [--return-to-base-branch|-r]         Return to base branch
     [--message|-m <commit-message>]      Override commit message
+    [--handover]                         Hand over uncommitted changes without a wip commit
+    [--no-handover]                      Hand over with a wip commit, even if MOB_NEXT_HANDOVER is set
+    [--interactive]                      Choose files to keep out of the handover
   done
     [--no-squash]                        Squash no commits from wip branch, only merge wip branch
     [--squash]                           Squash all commits from wip branch
     [--squash-wip]                       Squash wip commits from wip branch, maintaining manual commits
+    [--rebase]                           Squash wip commits and rebase manual commits onto base branch, without merge commit
+    [--pr]                               Push a review branch and open a pull request instead of merging locally
+    [--continue]                         Finish 'done' after solving merge conflicts
+    [--abort]                            Abort 'done' with merge conflicts and restore the wip branch
   reset
     [--branch|-b <branch-postfix>]       Set wip branch to 'mob/<base-branch>` + configuration.WipBranchQualifierSeparator + `<branch-postfix>'
+    [--archive]                          Archive the wip branch as 'refs/mob-archive/<wip-branch>/<timestamp>' on the remote first
+    [--no-archive]                       Do not archive the wip branch, even if MOB_RESET_ARCHIVE is set
+  clean
+    [--remote]                           Also remove remote wip branches without recent commits, after confirmation
+    [--older-than <age>]                 Remove remote wip branches without commits for <age>, e.g. '30d' (default MOB_SESSION_STALE_AFTER)
+    [--archive tag|bundle]               Archive remote wip branches as tags on the remote or as bundles in .git/mob-archive
+    [--yes|-y]                           Remove remote wip branches without asking
+  branch                                 Show all sessions grouped by base branch
+    [--json]                             Show the sessions as JSON
+  archive [list]                         Show the archived sessions on the remote
+  archive restore <id>                   Restore an archived session as its remote wip branch
+  sync                                   Merge the remote base branch into the wip branch and push it
+    [--rebase]                           Rebase the wip branch onto the remote base branch instead and force push it
+    [--merge]                            Merge, even if MOB_SYNC_REBASE is set
+  migrate                                Rename the legacy wip branch 'mob-session' to 'mob/master'
+    [--branch|-b <branch-postfix>]       Rename it to 'mob/master` + configuration.WipBranchQualifierSeparator + `<branch-postfix>' instead
+  with                                   Show who you declared to be mobbing with
+    [<alias>...]                         Add everyone in the session as co-authors, aliases are read from .mob-authors
+    [--clear]                            Forget who you are mobbing with
+
+Setup Commands:
+  init                                   Set up project and user configuration interactively
+    [--yes|-y]                           Use detected values without asking
+    [--project]                          Write the project .mob file (default)
+    [--user]                             Add missing voice and notify commands to the user ~/.mob file
+    [--remote <remote-name>]             Use <remote-name> as remote
+    [--room <room-name>]                 Set room name for timer.mob.sh
+    [--squash|--no-squash|--squash-wip|--rebase]
+                                         Set how 'done' treats the wip commits
 
 Timer Commands:
   timer <minutes>           Start a <minutes> timer
@@ -56,7 +94,7 @@ Short Commands (Options and descriptions as above):
 Get more information:
   status             Show status of the current session
   fetch              Fetch remote state
-  branch             Show remote wip branches
+  branch             Show all sessions
   config             Show all configuration options
   version            Show tool version
   help               Show help
@@ -64,6 +102,8 @@ Get more information:
 Other
   moo                Moo!
 
+Options take values as '--branch green' or '--branch=green', short options can be combined like '-ic'.
+Add '--profile <profile-name>' to any option to apply a profile from your ~/.mob file.
 Add '--debug' to any option to enable verbose logging.
 Need more help? Join the community at slack.mob.sh
 `
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/remotemobprogramming/mob/v5/say"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -79,3 +80,34 @@ func (c HttpClient) SendRequest(requestBody []byte, requestMethod string, reques
 	}
 	return body, nil
 }
+
+// SendRequestWithHeaders sends the request with additional headers (e.g. for authentication) without printing it,
+// the response body is part of the error to show the reason given by the server.
+func (c HttpClient) SendRequestWithHeaders(requestBody []byte, requestMethod string, requestUrl string, headers map[string]string) (string, error) {
+	say.Debug(requestMethod + " " + requestUrl + " " + string(requestBody))
+
+	request, requestCreationError := http.NewRequest(requestMethod, requestUrl, bytes.NewBuffer(requestBody))
+	if requestCreationError != nil {
+		return "", fmt.Errorf("failed to create the http request object: %w", requestCreationError)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	response, responseErr := c.netHttpClient.Do(request)
+	if responseErr != nil {
+		return "", fmt.Errorf("failed to make the http request: %w", responseErr)
+	}
+	defer response.Body.Close()
+	bodyBytes, responseReadingErr := io.ReadAll(response.Body)
+	if responseReadingErr != nil {
+		return "", fmt.Errorf("failed to read the http response: %w", responseReadingErr)
+	}
+	body := string(bodyBytes)
+	say.Debug(body)
+	if response.StatusCode >= 300 {
+		return body, errors.New("got an error from the server: " + requestUrl + " " + response.Status + " " + body)
+	}
+	return body, nil
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/exit"
+	"github.com/remotemobprogramming/mob/v5/input"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+var lookPath = exec.LookPath
+
+type ciSystem struct {
+	Name string
+	Path string
+	// whether the ci system understands the push option ci.skip
+	SupportsSkipCiPushOption bool
+}
+
+var ciSystems = []ciSystem{
+	{Name: "GitLab CI", Path: ".gitlab-ci.yml", SupportsSkipCiPushOption: true},
+	{Name: "GitHub Actions", Path: ".github/workflows"},
+	{Name: "Jenkins", Path: "Jenkinsfile"},
+	{Name: "CircleCI", Path: ".circleci"},
+	{Name: "Azure Pipelines", Path: "azure-pipelines.yml"},
+	{Name: "Bitbucket Pipelines", Path: "bitbucket-pipelines.yml"},
+	{Name: "Travis CI", Path: ".travis.yml"},
+}
+
+type initOptions struct {
+	nonInteractive bool
+	writeProject   bool
+	writeUser      bool
+	remoteName     string
+}
+
+func initMob(configuration config.Configuration, parameters []string) {
+	if err := initConfiguration(configuration, parameters); err != nil {
+		say.Error(err.Error())
+		exit.Exit(1)
+	}
+}
+
+func initConfiguration(configuration config.Configuration, parameters []string) error {
+	if !isGit() {
+		return errors.New("expecting the current working directory to be a git repository.")
+	}
+	options, err := parseInitOptions(parameters)
+	if err != nil {
+		return err
+	}
+	interactive := !options.nonInteractive && input.IsInteractive()
+	rootDir := gitRootDir()
+
+	remoteName := options.remoteName
+	if remoteName == "" {
+		remoteName = detectRemoteName(configuration)
+	}
+	say.Info("remote: " + remoteName)
+
+	defaultBranch := detectDefaultBranch(configuration, remoteName)
+	say.Info("default branch: " + defaultBranch)
+
+	skipCiPushOptionEnabled := false
+	ci, found := detectCiSystem(rootDir)
+	if found {
+		say.Info("ci system: " + ci.Name)
+		skipCiPushOptionEnabled = ci.SupportsSkipCiPushOption
+	} else {
+		say.Info("ci system: none detected")
+	}
+
+	voiceCommand := detectVoiceCommand()
+	notifyCommand := detectNotifyCommand()
+	say.Info("voice command: " + describeCommand(voiceCommand))
+	say.Info("notify command: " + describeCommand(notifyCommand))
+
+	timerRoom := configuration.TimerRoom
+	doneSquash := configuration.DoneSquash
+	// the user configuration is only touched on request, it belongs to the user and not to the project
+	writeProject, writeUser := options.writeProject || !options.writeUser, options.writeUser
+
+	if interactive {
+		remoteName = input.Ask("Which remote do you use?", remoteName)
+		skipCiPushOptionEnabled = askBool("Skip ci on wip pushes with the push option ci.skip?", skipCiPushOptionEnabled)
+		timerRoom = input.Ask("Which room do you use on timer.mob.sh? (leave empty for none)", timerRoom)
+		doneSquash = input.Ask("How should 'mob done' treat the wip commits? ("+config.Squash+", "+config.NoSquash+", "+config.SquashWip+", "+config.Rebase+")", doneSquash)
+		writeProject = writeProject && input.Confirm("Write project configuration to "+projectConfigurationPath(rootDir)+"?")
+		writeUser = writeUser && input.Confirm("Add missing voice and notify commands to "+config.UserConfigurationPath()+"?")
+	}
+
+	if err := validateInitValues(remoteName, timerRoom, doneSquash); err != nil {
+		return err
+	}
+
+	if writeProject {
+		settings := []config.Setting{
+			config.StringSetting("MOB_REMOTE_NAME", remoteName),
+			config.BoolSetting("MOB_SKIP_CI_PUSH_OPTION_ENABLED", skipCiPushOptionEnabled),
+			{Key: "MOB_DONE_SQUASH", Value: doneSquash},
+		}
+		if defaultBranch == legacyBaseBranch {
+			// 'mob start' on master uses mob/master like every other base branch, unless a legacy session is still going on
+			settings = append(settings, config.BoolSetting("MOB_LEGACY_MOB_SESSION", stringContains(gitRemoteBranches(), remoteName+"/"+legacyWipBranch)))
+		}
+		if timerRoom != "" {
+			settings = append(settings, config.StringSetting("MOB_TIMER_ROOM", timerRoom))
+		}
+		if err := config.WriteSettings(projectConfigurationPath(rootDir), settings); err != nil {
+			return err
+		}
+		say.Info("wrote project configuration to " + projectConfigurationPath(rootDir))
+	}
+
+	if writeUser {
+		var settings []config.Setting
+		if voiceCommand != "" {
+			settings = append(settings, config.StringSetting("MOB_VOICE_COMMAND", voiceCommand))
+		}
+		if notifyCommand != "" {
+			settings = append(settings, config.StringSetting("MOB_NOTIFY_COMMAND", notifyCommand))
+		}
+		added, err := config.AddMissingSettings(config.UserConfigurationPath(), settings)
+		if err != nil {
+			return err
+		}
+		if len(added) == 0 {
+			say.Info("kept user configuration " + config.UserConfigurationPath() + " unchanged")
+		} else {
+			say.Info("added " + strings.Join(added, ", ") + " to user configuration " + config.UserConfigurationPath())
+		}
+	}
+
+	if writeProject {
+		say.Next("Share the project configuration with your team, use", "git add .mob && git commit -m \"add mob configuration\"")
+	}
+	return nil
+}
+
+func parseInitOptions(parameters []string) (initOptions, error) {
+	options := initOptions{}
+	for i := 0; i < len(parameters); i++ {
+		switch parameters[i] {
+		case "--yes", "-y":
+			options.nonInteractive = true
+		case "--project":
+			options.writeProject = true
+		case "--user":
+			options.writeUser = true
+		case "--remote":
+			if i+1 == len(parameters) {
+				return options, errors.New("missing value for --remote")
+			}
+			options.remoteName = parameters[i+1]
+			i++ // skip consumed parameter
+		default:
+			return options, errors.New("unknown option for init: " + parameters[i])
+		}
+	}
+	return options, nil
+}
+
+func validateInitValues(remoteName string, timerRoom string, doneSquash string) error {
+	if !stringContains(gitRemotes(), remoteName) {
+		return errors.New("remote '" + remoteName + "' does not exist")
+	}
+	if strings.ContainsAny(timerRoom, " \t/") {
+		return errors.New("timer room '" + timerRoom + "' must not contain whitespace or slashes")
+	}
+	switch doneSquash {
+	case config.Squash, config.NoSquash, config.SquashWip, config.Rebase:
+	default:
+		return errors.New("unknown squash mode '" + doneSquash + "', use " + config.Squash + ", " + config.NoSquash + ", " + config.SquashWip + " or " + config.Rebase)
+	}
+	return nil
+}
+
+func askBool(question string, defaultAnswer bool) bool {
+	for {
+		answer, err := strconv.ParseBool(input.Ask(question, strconv.FormatBool(defaultAnswer)))
+		if err == nil {
+			return answer
+		}
+		say.Warning("please answer with true or false")
+	}
+}
+
+func projectConfigurationPath(rootDir string) string {
+	return rootDir + "/.mob"
+}
+
+func gitRemotes() []string {
+	output := silentgit("remote")
+	if output == "" {
+		return []string{}
+	}
+	return strings.Split(output, "\n")
+}
+
+func detectRemoteName(configuration config.Configuration) string {
+	remotes := gitRemotes()
+	if stringContains(remotes, configuration.RemoteName) || len(remotes) == 0 {
+		return configuration.RemoteName
+	}
+	return remotes[0]
+}
+
+// the branch HEAD of the remote points to, else the base branch of the current branch
+func detectDefaultBranch(configuration config.Configuration, remoteName string) string {
+	defaultBranch, err := silentgitignorefailure("symbolic-ref", "--short", "refs/remotes/"+remoteName+"/HEAD")
+	if err == nil && defaultBranch != "" {
+		return strings.TrimPrefix(defaultBranch, remoteName+"/")
+	}
+	baseBranch, _ := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	return baseBranch.Name
+}
+
+func detectCiSystem(rootDir string) (ciSystem, bool) {
+	for _, ci := range ciSystems {
+		if _, err := os.Stat(filepath.Join(rootDir, ci.Path)); err == nil {
+			return ci, true
+		}
+	}
+	return ciSystem{}, false
+}
+
+func detectVoiceCommand() string {
+	switch runtime.GOOS {
+	case "windows":
+		return config.GetDefaultConfiguration().VoiceCommand
+	}
+	for _, command := range []string{"say", "espeak-ng", "espeak"} {
+		if _, err := lookPath(command); err == nil {
+			return command + " \"%s\""
+		}
+	}
+	return ""
+}
+
+func detectNotifyCommand() string {
+	switch runtime.GOOS {
+	case "windows":
+		return ""
+	case "darwin":
+		return config.GetDefaultConfiguration().NotifyCommand
+	}
+	if _, err := lookPath("notify-send"); err == nil {
+		return "notify-send \"%s\""
+	}
+	return ""
+}
+
+func describeCommand(command string) string {
+	if command == "" {
+		return "none found"
+	}
+	return command
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/input"
+)
+
+func TestInitNonInteractive(t *testing.T) {
+	output, configuration := setup(t)
+	userHomeDir := t.TempDir()
+	t.Setenv("HOME", userHomeDir)
+	mockLookPath(t, "espeak", "notify-send")
+	createFile(t, ".gitlab-ci.yml", "")
+	configuration.TimerRoom = "testroom"
+	configuration.DoneSquash = config.SquashWip
+
+	err := initConfiguration(configuration, []string{"--yes"})
+
+	assertNoError(t, err)
+	assertOutputContains(t, output, "default branch: master")
+	assertOutputContains(t, output, "ci system: GitLab CI")
+	equals(t, "MOB_REMOTE_NAME=\"origin\"\nMOB_SKIP_CI_PUSH_OPTION_ENABLED=true\nMOB_DONE_SQUASH=squash-wip\nMOB_LEGACY_MOB_SESSION=false\nMOB_TIMER_ROOM=\"testroom\"\n", readFile(t, tempDir+"/local/.mob"))
+	_, err = os.Stat(userHomeDir + "/.mob")
+	equals(t, true, os.IsNotExist(err))
+}
+
+func TestInitUser(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("detects linux commands")
+	}
+	_, configuration := setup(t)
+	userHomeDir := t.TempDir()
+	t.Setenv("HOME", userHomeDir)
+	mockLookPath(t, "espeak", "notify-send")
+
+	err := initConfiguration(configuration, []string{"--yes", "--user"})
+
+	assertNoError(t, err)
+	equals(t, "MOB_VOICE_COMMAND=\"espeak \\\"%s\\\"\"\nMOB_NOTIFY_COMMAND=\"notify-send \\\"%s\\\"\"\n", readFile(t, userHomeDir+"/.mob"))
+	_, err = os.Stat(tempDir + "/local/.mob")
+	equals(t, true, os.IsNotExist(err))
+}
+
+func TestInitUserKeepsExistingCommands(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("detects linux commands")
+	}
+	output, configuration := setup(t)
+	userHomeDir := t.TempDir()
+	t.Setenv("HOME", userHomeDir)
+	mockLookPath(t, "espeak", "notify-send")
+	os.WriteFile(userHomeDir+"/.mob", []byte("MOB_VOICE_COMMAND=\"my-voice \\\"%s\\\"\"\n"), 0644)
+
+	err := initConfiguration(configuration, []string{"--yes", "--user"})
+
+	assertNoError(t, err)
+	assertOutputContains(t, output, "added MOB_NOTIFY_COMMAND to user configuration")
+	equals(t, "MOB_VOICE_COMMAND=\"my-voice \\\"%s\\\"\"\nMOB_NOTIFY_COMMAND=\"notify-send \\\"%s\\\"\"\n", readFile(t, userHomeDir+"/.mob"))
+}
+
+func TestInitNonInteractiveOnlyProjectKeepsExistingSettings(t *testing.T) {
+	_, configuration := setup(t)
+	userHomeDir := t.TempDir()
+	t.Setenv("HOME", userHomeDir)
+	createFile(t, ".mob", "MOB_TIMER=\"10\"\nMOB_REMOTE_NAME=\"upstream\"\n")
+
+	err := initConfiguration(configuration, []string{"--yes", "--project"})
+
+	assertNoError(t, err)
+	equals(t, "MOB_TIMER=\"10\"\nMOB_REMOTE_NAME=\"origin\"\nMOB_SKIP_CI_PUSH_OPTION_ENABLED=false\nMOB_DONE_SQUASH=squash\nMOB_LEGACY_MOB_SESSION=false\n", readFile(t, tempDir+"/local/.mob"))
+	_, err = os.Stat(userHomeDir + "/.mob")
+	equals(t, true, os.IsNotExist(err))
+}
+
+func TestInitInteractive(t *testing.T) {
+	_, configuration := setup(t)
+	userHomeDir := t.TempDir()
+	t.Setenv("HOME", userHomeDir)
+	mockLookPath(t)
+	mockInteractiveInput(t, "\nfalse\nmy-room\nno-squash\ny\n")
+
+	err := initConfiguration(configuration, []string{})
+
+	assertNoError(t, err)
+	equals(t, "MOB_REMOTE_NAME=\"origin\"\nMOB_SKIP_CI_PUSH_OPTION_ENABLED=false\nMOB_DONE_SQUASH=no-squash\nMOB_LEGACY_MOB_SESSION=false\nMOB_TIMER_ROOM=\"my-room\"\n", readFile(t, tempDir+"/local/.mob"))
+	_, err = os.Stat(userHomeDir + "/.mob")
+	equals(t, true, os.IsNotExist(err))
+}
+
+func TestInitDetectsDefaultBranchOfRemote(t *testing.T) {
+	output, configuration := setup(t)
+	t.Setenv("HOME", t.TempDir())
+	git("checkout", "-b", "main")
+	git("push", "origin", "main")
+	git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	git("checkout", "master")
+
+	err := initConfiguration(configuration, []string{"--yes"})
+
+	assertNoError(t, err)
+	assertOutputContains(t, output, "default branch: main")
+	equals(t, "MOB_REMOTE_NAME=\"origin\"\nMOB_SKIP_CI_PUSH_OPTION_ENABLED=false\nMOB_DONE_SQUASH=squash\n", readFile(t, tempDir+"/local/.mob"))
+}
+
+func TestInitDetectsDefaultBranchFromCurrentBranchAndKeepsLegacyMobSession(t *testing.T) {
+	output, configuration := setup(t)
+	t.Setenv("HOME", t.TempDir())
+	start(configuration)
+
+	err := initConfiguration(configuration, []string{"--yes"})
+
+	assertNoError(t, err)
+	assertOutputContains(t, output, "default branch: master")
+	equals(t, "MOB_REMOTE_NAME=\"origin\"\nMOB_SKIP_CI_PUSH_OPTION_ENABLED=false\nMOB_DONE_SQUASH=squash\nMOB_LEGACY_MOB_SESSION=true\n", readFile(t, tempDir+"/local/.mob"))
+}
+
+func TestInitRejectsUnknownRemote(t *testing.T) {
+	_, configuration := setup(t)
+	t.Setenv("HOME", t.TempDir())
+
+	err := initConfiguration(configuration, []string{"--yes", "--remote", "unknown"})
+
+	assertError(t, err, "remote 'unknown' does not exist")
+}
+
+func TestInitRejectsUnknownOption(t *testing.T) {
+	_, configuration := setup(t)
+
+	err := initConfiguration(configuration, []string{"--unknown"})
+
+	assertError(t, err, "unknown option for init: --unknown")
+}
+
+func mockLookPath(t *testing.T, availableCommands ...string) {
+	originalLookPath := lookPath
+	lookPath = func(file string) (string, error) {
+		if stringContains(availableCommands, file) {
+			return "/usr/bin/" + file, nil
+		}
+		return "", os.ErrNotExist
+	}
+	t.Cleanup(func() {
+		lookPath = originalLookPath
+	})
+}
+
+func mockInteractiveInput(t *testing.T, text string) {
+	originalIn, originalIsInteractive := input.In, input.IsInteractive
+	input.In = strings.NewReader(text)
+	input.IsInteractive = func() bool { return true }
+	t.Cleanup(func() {
+		input.In, input.IsInteractive = originalIn, originalIsInteractive
+	})
+}
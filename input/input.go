@@ -0,0 +1,59 @@
+package input
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+var In io.Reader = os.Stdin
+
+// IsInteractive is true if stdin is a terminal, /dev/null is a character device as well
+var IsInteractive = func() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(stat, null)
+}
+
+// reads byte by byte, so that no input is lost in a buffer between two calls
+func ReadLine() string {
+	var line strings.Builder
+	buffer := make([]byte, 1)
+	for {
+		n, err := In.Read(buffer)
+		if n > 0 {
+			if buffer[0] == '\n' {
+				break
+			}
+			line.WriteByte(buffer[0])
+		}
+		if err != nil {
+			break
+		}
+	}
+	return strings.TrimSpace(line.String())
+}
+
+func Confirm(question string) bool {
+	say.PrintToConsole(question + " [y/N] ")
+	answer := strings.ToLower(ReadLine())
+	return answer == "y" || answer == "yes"
+}
+
+func Ask(question string, defaultAnswer string) string {
+	if defaultAnswer != "" {
+		say.PrintToConsole(question + " [" + defaultAnswer + "] ")
+	} else {
+		say.PrintToConsole(question + " ")
+	}
+	answer := ReadLine()
+	if answer == "" {
+		return defaultAnswer
+	}
+	return answer
+}
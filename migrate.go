@@ -0,0 +1,63 @@
+package main
+
+import (
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/exit"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// DEPRECATED: before wip branches were named after their base branch, every session of 'master' used 'mob-session'
+const (
+	legacyWipBranch  = "mob-session"
+	legacyBaseBranch = "master"
+)
+
+// renames the legacy wip branch 'mob-session' on the remote and locally to 'mob/master' (or 'mob/master-<qualifier>')
+func migrate(configuration config.Configuration) {
+	git("fetch", configuration.RemoteName, "--prune")
+
+	legacy := newBranch(legacyWipBranch)
+	target := newBranch(legacyBaseBranch).addWipPrefix(configuration).addWipQualifier(configuration)
+	hasLocal, hasRemote := legacy.hasLocalBranch(), legacy.hasRemoteBranch(configuration)
+	if !hasLocal && !hasRemote {
+		say.Info("there is no legacy session '" + legacyWipBranch + "' to migrate")
+		sayDisableLegacyMobSession(configuration)
+		return
+	}
+	if target.hasLocalBranch() || target.hasRemoteBranch(configuration) {
+		say.Error("Cannot migrate '" + legacyWipBranch + "' to '" + target.Name + "', because '" + target.Name + "' exists.")
+		say.Fix("To migrate to a session with a qualifier, use", configuration.Mob("migrate --branch <branch-postfix>"))
+		exit.Exit(1)
+		return
+	}
+
+	if hasRemote {
+		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "refs/remotes/"+legacy.remote(configuration).Name+":refs/heads/"+target.Name, shareBaseBranchRefspec(target, newBranch(legacyBaseBranch)))
+		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", legacy.Name)
+		git("fetch", configuration.RemoteName, "--prune")
+	}
+	if hasLocal {
+		git("branch", "--move", legacy.Name, target.Name)
+		rememberBaseBranch(target, newBranch(legacyBaseBranch))
+		if hasRemote {
+			git("branch", "--set-upstream-to="+target.remote(configuration).Name, target.Name)
+		}
+	}
+	say.Info("migrated legacy session '" + legacyWipBranch + "' to '" + target.Name + "'")
+	sayDisableLegacyMobSession(configuration)
+}
+
+func sayDisableLegacyMobSession(configuration config.Configuration) {
+	if configuration.LegacyMobSession {
+		say.Fix("To stop using '"+legacyWipBranch+"' for '"+legacyBaseBranch+"', add this line to the .mob file of your project", "MOB_LEGACY_MOB_SESSION=false")
+	}
+}
+
+// warns about a legacy session that mob ignores, because MOB_LEGACY_MOB_SESSION is turned off
+func warnAboutLegacySession(configuration config.Configuration, currentBaseBranch Branch) {
+	if configuration.LegacyMobSession || !currentBaseBranch.Is(legacyBaseBranch) || !newBranch(legacyWipBranch).hasRemoteBranch(configuration) {
+		return
+	}
+	say.Warning("There is a legacy session " + newBranch(legacyWipBranch).remote(configuration).Name + ", which is ignored.")
+	say.Fix("To continue it as '"+legacyBaseBranch+"' session, use", configuration.Mob("migrate"))
+}
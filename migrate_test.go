@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestMigrateLegacySession(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	legacyCommit := silentgit("rev-parse", "origin/mob-session")
+
+	migrate(configuration)
+
+	assertOutputContains(t, output, "migrated legacy session 'mob-session' to 'mob/master'")
+	assertOutputContains(t, output, "MOB_LEGACY_MOB_SESSION=false")
+	equals(t, false, newBranch("mob-session").hasRemoteBranch(configuration))
+	equals(t, false, newBranch("mob-session").hasLocalBranch())
+	equals(t, legacyCommit, silentgit("rev-parse", "origin/mob/master"))
+	equals(t, legacyCommit, silentgit("rev-parse", "mob/master"))
+}
+
+func TestMigrateLegacySessionWhileOnIt(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	createFileAndCommitIt(t, "unpushed.txt", "contentIrrelevant", "unpushed commit")
+	localCommit := silentgit("rev-parse", "HEAD")
+
+	migrate(configuration)
+
+	assertOnBranch(t, "mob/master")
+	equals(t, localCommit, silentgit("rev-parse", "HEAD"))
+	equals(t, "origin/mob/master", silentgit("rev-parse", "--abbrev-ref", "mob/master@{upstream}"))
+}
+
+func TestMigrateLegacySessionWithQualifier(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	configuration.WipBranchQualifier = "green"
+
+	migrate(configuration)
+
+	equals(t, true, newBranch("mob/master-green").hasRemoteBranch(configuration))
+	equals(t, false, newBranch("mob-session").hasRemoteBranch(configuration))
+}
+
+func TestMigrateFailsWhenTargetExists(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	git("push", "origin", "mob-session:mob/master")
+	mockExit()
+	defer resetExit()
+
+	migrate(configuration)
+
+	assertOutputContains(t, output, "Cannot migrate 'mob-session' to 'mob/master', because 'mob/master' exists.")
+	equals(t, true, newBranch("mob-session").hasRemoteBranch(configuration))
+}
+
+func TestMigrateWithoutLegacySession(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.LegacyMobSession = false
+
+	migrate(configuration)
+
+	assertOutputContains(t, output, "there is no legacy session 'mob-session' to migrate")
+	assertOutputNotContains(t, output, "MOB_LEGACY_MOB_SESSION=false")
+}
+
+func TestStartWithoutLegacyMobSession(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.LegacyMobSession = false
+
+	start(configuration)
+
+	assertOnBranch(t, "mob/master")
+	assertMobSessionBranches(t, configuration, "mob/master")
+}
+
+func TestStartWithoutLegacyMobSessionWarnsAboutLegacySession(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	git("checkout", "master")
+	configuration.LegacyMobSession = false
+
+	start(configuration)
+
+	assertOutputContains(t, output, "There is a legacy session origin/mob-session, which is ignored.")
+	assertOutputContains(t, output, "mob migrate")
+	assertOnBranch(t, "mob/master")
+}
+
+func TestDetermineBranchesWithoutLegacyMobSession(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+	configuration.LegacyMobSession = false
+
+	baseBranch, wipBranch := determineBranches(newBranch("master"), []string{"master"}, configuration)
+
+	equals(t, "master", baseBranch.Name)
+	equals(t, "mob/master", wipBranch.Name)
+	equals(t, false, newBranch("mob-session").IsWipBranch(configuration))
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -27,7 +28,7 @@ import (
 
 const (
 	versionNumber     = "5.4.2"
-	minimumGitVersion = "2.13.0"
+	minimumGitVersion = "2.22.0"
 )
 
 var (
@@ -35,18 +36,6 @@ var (
 	args []string
 )
 
-func openCommandFor(c config.Configuration, filepath string) (string, []string) {
-	if !c.IsOpenCommandGiven() {
-		return "", []string{}
-	}
-	filepathWithoutSpaces := strings.ReplaceAll(filepath, " ", "&spc&")
-	split := strings.Split(injectCommandWithMessage(c.OpenCommand, filepathWithoutSpaces), " ")
-	for i := 0; i < len(split); i++ {
-		split[i] = strings.ReplaceAll(split[i], "&spc&", " ")
-	}
-	return split[0], split[1:]
-}
-
 type GitVersion = mobgit.GitVersion
 
 func parseGitVersion(version string) GitVersion {
@@ -105,7 +94,7 @@ func (branch Branch) hasLocalBranch() bool {
 }
 
 func (branch Branch) IsWipBranch(configuration config.Configuration) bool {
-	if branch.Name == "mob-session" {
+	if configuration.LegacyMobSession && branch.Name == legacyWipBranch {
 		return true
 	}
 
@@ -174,8 +163,20 @@ func (branch Branch) exists(existingBranches []string) bool {
 	return stringContains(existingBranches, branch.Name)
 }
 
-func (branch Branch) hasWipBranchQualifierSeparator(configuration config.Configuration) bool { //TODO improve (dont use strings.Contains, add tests)
-	return strings.Contains(branch.Name, configuration.WipBranchQualifierSeparator)
+func (branch Branch) hasWipBranchQualifierSeparator(configuration config.Configuration) bool {
+	if configuration.CustomWipBranchQualifierConfigured() {
+		return strings.HasSuffix(branch.Name, configuration.WipBranchQualifierSuffix())
+	}
+	if configuration.WipBranchQualifierSeparator == "" {
+		return false
+	}
+	// ends with <separator><qualifier>, a qualifier is neither empty nor a directory of the branch name
+	index := strings.LastIndex(branch.Name, configuration.WipBranchQualifierSeparator)
+	if index <= 0 {
+		return false
+	}
+	qualifier := branch.Name[index+len(configuration.WipBranchQualifierSeparator):]
+	return qualifier != "" && !strings.Contains(qualifier, "/")
 }
 
 func (branch Branch) hasLocalCommits(configuration config.Configuration) bool {
@@ -250,7 +251,13 @@ func run(osArgs []string) {
 		configuration.CliName = currentCliName
 	}
 
-	command, parameters, configuration := config.ParseArgs(args, configuration)
+	command, parameters, configuration, err := config.ParseArgs(args, configuration)
+	if err != nil {
+		say.Error(err.Error())
+		say.Fix("To see all available commands and options, use", configuration.Mob("help"))
+		exit.Exit(1)
+		return
+	}
 	say.Debug("command '" + command + "'")
 	say.Debug("parameters '" + strings.Join(parameters, " ") + "'")
 	say.Debug("version " + versionNumber)
@@ -293,19 +300,33 @@ func execute(command string, parameter []string, configuration config.Configurat
 			say.Info("It's now " + currentTime() + ". Happy collaborating! :)")
 		}
 	case "b", "branch":
-		branch(configuration)
+		branch(configuration, parameter)
 	case "n", "next":
 		next(configuration)
 	case "d", "done":
-		done(configuration)
+		if stringContains(parameter, "--continue") {
+			doneContinue(configuration)
+		} else if stringContains(parameter, "--abort") {
+			doneAbort(configuration)
+		} else {
+			done(configuration)
+		}
 	case "fetch":
 		fetch(configuration)
 	case "reset":
 		reset(configuration)
 	case "clean":
-		clean(configuration)
+		cleanRemote(configuration, parameter)
+	case "archive":
+		archive(configuration, parameter)
+	case "migrate":
+		migrate(configuration)
+	case "sync":
+		syncSession(configuration)
 	case "config":
 		config.Config(configuration)
+	case "init":
+		initMob(configuration, parameter)
 	case "status":
 		status(configuration)
 	case "t", "timer":
@@ -331,14 +352,12 @@ func execute(command string, parameter []string, configuration config.Configurat
 		}
 	case "moo":
 		localtimer.Moo(configuration)
-	case "sw", "squash-wip":
-		if len(parameter) > 1 && parameter[0] == "--git-editor" {
-			squashWipGitEditor(parameter[1], configuration)
-		} else if len(parameter) > 1 && parameter[0] == "--git-sequence-editor" {
-			squashWipGitSequenceEditor(parameter[1], configuration)
-		}
 	case "g", "goal":
-		goal.Goal(configuration, parameter)
+		if currentGoal, err := goal.Goal(configuration, parameter); err == nil {
+			rememberGoal(currentGoal)
+		}
+	case "with":
+		with(configuration, parameter)
 	case "version", "--version", "-v":
 		version()
 	case "help", "--help", "-h":
@@ -407,20 +426,13 @@ func (branch Branch) isOrphanWipBranch(configuration config.Configuration) bool
 	return branch.IsWipBranch(configuration) && !branch.hasRemoteBranch(configuration)
 }
 
-func branch(configuration config.Configuration) {
-	say.Say(silentgit("branch", "--list", "--remote", newBranch("*").addWipPrefix(configuration).remote(configuration).Name))
-
-	// DEPRECATED
-	say.Say(silentgit("branch", "--list", "--remote", newBranch("mob-session").remote(configuration).Name))
-}
-
 func determineBranches(currentBranch Branch, localBranches []string, configuration config.Configuration) (baseBranch Branch, wipBranch Branch) {
-	if currentBranch.Is("mob-session") || (currentBranch.Is("master") && !configuration.CustomWipBranchQualifierConfigured()) {
-		// DEPRECATED
-		baseBranch = newBranch("master")
-		wipBranch = newBranch("mob-session")
+	if configuration.LegacyMobSession && (currentBranch.Is(legacyWipBranch) || (currentBranch.Is(legacyBaseBranch) && !configuration.CustomWipBranchQualifierConfigured())) {
+		// DEPRECATED, use 'mob migrate' and MOB_LEGACY_MOB_SESSION=false
+		baseBranch = newBranch(legacyBaseBranch)
+		wipBranch = newBranch(legacyWipBranch)
 	} else if currentBranch.IsWipBranch(configuration) {
-		baseBranch = currentBranch.removeWipPrefix(configuration).removeWipQualifier(localBranches, configuration)
+		baseBranch = baseBranchOf(currentBranch, localBranches, configuration)
 		wipBranch = currentBranch
 	} else {
 		baseBranch = currentBranch
@@ -445,26 +457,15 @@ func enrichConfigurationWithBranchQualifier(configuration config.Configuration)
 		currentBaseBranch, _ := determineBranches(currentBranch, gitBranches(), configuration)
 
 		if currentBranch.IsWipBranch(configuration) {
-			wipBranchWithoutWipPrefix := currentBranch.removeWipPrefix(configuration).Name
-			configuration.WipBranchQualifier = removePrefix(removePrefix(wipBranchWithoutWipPrefix, currentBaseBranch.Name), configuration.WipBranchQualifierSeparator)
+			if qualifier := wipBranchQualifierOf(currentBranch, currentBaseBranch, configuration); qualifier != nil {
+				configuration.WipBranchQualifier = *qualifier
+			}
 		}
 	}
 
 	return configuration
 }
 
-func injectCommandWithMessage(command string, message string) string {
-	placeHolders := strings.Count(command, "%s")
-	if placeHolders > 1 {
-		say.Error(fmt.Sprintf("Too many placeholders (%d) in format command string: %s", placeHolders, command))
-		exit.Exit(1)
-	}
-	if placeHolders == 0 {
-		return fmt.Sprintf("%s %s", command, message)
-	}
-	return fmt.Sprintf(command, message)
-}
-
 func currentTime() string {
 	return time.Now().Format("15:04")
 }
@@ -483,18 +484,29 @@ func deleteRemoteWipBranch(configuration config.Configuration) {
 
 	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 
+	if configuration.ResetArchive && !archiveWipBranch(configuration, currentWipBranch) {
+		say.Error("Could not archive " + currentWipBranch.String() + ", nothing was deleted.")
+		say.Fix("To delete the wip branch without archiving it, use", configuration.Mob("reset --delete-remote-wip-branch --no-archive"))
+		exit.Exit(1)
+		return
+	}
+
 	git("checkout", currentBaseBranch.String())
 	if currentWipBranch.hasLocalBranch() {
 		git("branch", "--delete", "--force", currentWipBranch.String())
 	}
+	deleteHandover(configuration, currentWipBranch)
 	if currentWipBranch.hasRemoteBranch(configuration) {
-		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", currentWipBranch.String())
+		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", currentWipBranch.String(), sharedBaseBranchRefToDelete(currentWipBranch))
 	}
+	forgetBaseBranch(currentWipBranch)
+	clearParticipants()
 	say.Info("Branches " + currentWipBranch.String() + " and " + currentWipBranch.remote(configuration).String() + " deleted")
 }
 
 func start(configuration config.Configuration) error {
 	uncommittedChanges := hasUncommittedChanges()
+	includedChanges := false
 	if uncommittedChanges && configuration.HandleUncommittedChanges == config.FailWithError {
 		say.Info("cannot start; clean working tree required")
 		sayUnstagedChangesInfo()
@@ -503,9 +515,22 @@ func start(configuration config.Configuration) error {
 		return errors.New("cannot start; clean working tree required")
 	}
 
+	fetchBaseBranchesWithRemote(configuration)
 	git("fetch", configuration.RemoteName, "--prune")
+	if configuration.StartStack {
+		return startStackedSession(configuration, uncommittedChanges)
+	}
 	currentBranch := gitCurrentBranch()
 	currentBaseBranch, currentWipBranch := determineBranches(currentBranch, gitBranches(), configuration)
+	warnAboutLegacySession(configuration, currentBaseBranch)
+
+	if shouldPickSession(configuration, currentWipBranch) {
+		var err error
+		if configuration, err = pickSession(configuration, currentBaseBranch); err != nil {
+			return err
+		}
+		currentBaseBranch, currentWipBranch = determineBranches(currentBranch, gitBranches(), configuration)
+	}
 
 	if !currentWipBranch.hasRemoteBranch(configuration) && configuration.StartJoin {
 		say.Error("Remote wip branch " + currentWipBranch.remote(configuration).String() + " is missing")
@@ -536,8 +561,9 @@ func start(configuration config.Configuration) error {
 			say.Fix("to fix this, go to the parent directory and try again", "cd ..")
 			return errors.New("cannot start; current working dir is an uncommitted subdir")
 		}
-		git("stash", "push", "--include-untracked", "--message", configuration.StashName)
-		say.Info("uncommitted changes were stashed. If an error occurs later on, you can recover them with 'git stash pop'.")
+		if includedChanges = stashChangesToHandOver(configuration.StashName, nil); includedChanges {
+			say.Info("uncommitted changes were stashed. If an error occurs later on, you can recover them with 'git stash pop'.")
+		}
 	}
 
 	if !isMobProgramming(configuration) {
@@ -546,22 +572,34 @@ func start(configuration config.Configuration) error {
 
 	if currentWipBranch.hasRemoteBranch(configuration) {
 		startJoinMobSession(configuration)
+		if !uncommittedChanges {
+			restoreHandover(configuration, currentWipBranch)
+		}
+		if configuration.StartSync && !hasUncommittedChanges() {
+			syncWipBranch(configuration, currentBaseBranch, currentWipBranch)
+		} else {
+			sayBehindBaseBranch(configuration, currentBaseBranch, currentWipBranch)
+		}
 	} else {
 		warnForActiveWipBranches(configuration, currentBaseBranch)
 
 		startNewMobSession(configuration)
 	}
 
-	if uncommittedChanges && configuration.HandleUncommittedChanges == config.IncludeChanges {
+	if includedChanges {
 		stashes := silentgit("stash", "list")
 		stash := findStashByName(stashes, configuration.StashName)
 		git("stash", "pop", stash)
 	}
 
+	rememberBaseBranch(currentWipBranch, currentBaseBranch)
 	say.Info("you are on wip branch '" + currentWipBranch.String() + "' (base branch '" + currentBaseBranch.String() + "')")
 	sayLastCommitsList(currentBaseBranch, currentWipBranch, configuration)
+	if lastCommitIsWipCommit(configuration) {
+		sayLastRotation(parseWipCommitMetadata(lastCommitMessage()))
+	}
 
-	openLastModifiedFileIfPresent(configuration)
+	openLastModifiedFilesIfPresent(configuration)
 
 	return nil // no error
 }
@@ -600,18 +638,7 @@ func branchFix(configuration config.Configuration) string {
 }
 
 func branchParameter(configuration config.Configuration) bool {
-	return containsAny(args, "-b", "--branch") && configuration.WipBranchQualifier != ""
-}
-
-func containsAny(list []string, elements ...string) bool {
-	for _, value := range list {
-		for _, element := range elements {
-			if value == element {
-				return true
-			}
-		}
-	}
-	return false
+	return config.IsOptionGiven(args, "branch") && configuration.WipBranchQualifier != ""
 }
 
 func createRemoteBranch(configuration config.Configuration, currentBaseBranch Branch) {
@@ -622,46 +649,6 @@ func createRemoteBranch(configuration config.Configuration, currentBaseBranch Br
 	}
 }
 
-func openLastModifiedFileIfPresent(configuration config.Configuration) {
-	if !configuration.IsOpenCommandGiven() {
-		say.Debug("No open command given")
-		return
-	}
-
-	say.Debug("Try to open last modified file")
-	if !lastCommitIsWipCommit(configuration) {
-		say.Debug("Last commit isn't a WIP commit.")
-		return
-	}
-	lastCommitMessage := lastCommitMessage()
-	split := strings.Split(lastCommitMessage, "lastFile:")
-	if len(split) == 1 {
-		say.Warning("Couldn't find last modified file in commit message!")
-		return
-	}
-	if len(split) > 2 {
-		say.Warning("Could not determine last modified file from commit message, separator was used multiple times!")
-		return
-	}
-	lastModifiedFile := strings.Split(split[1], "\n")[0]
-	if strings.HasPrefix(lastModifiedFile, "\"") {
-		lastModifiedFile, _ = strconv.Unquote(lastModifiedFile)
-	}
-	if lastModifiedFile == "" {
-		say.Debug("Could not find last modified file in commit message")
-		return
-	}
-	lastModifiedFilePath := gitRootDir() + "/" + lastModifiedFile
-	commandname, args := openCommandFor(configuration, lastModifiedFilePath)
-	_, err := startCommand(commandname, args...)
-	if err != nil {
-		say.Warning(fmt.Sprintf("Couldn't open last modified file on your system (%s)", runtime.GOOS))
-		say.Warning(err.Error())
-		return
-	}
-	say.Debug("Open last modified file: " + lastModifiedFilePath)
-}
-
 func warnForActiveWipBranches(configuration config.Configuration, currentBaseBranch Branch) {
 	if isMobProgramming(configuration) {
 		return
@@ -701,9 +688,9 @@ func getWipBranchesForBaseBranch(currentBaseBranch Branch, configuration config.
 
 	remoteBranchWithQualifier := currentBaseBranch.addWipPrefix(configuration).addWipQualifier(configuration).remote(configuration).Name
 	remoteBranchNoQualifier := currentBaseBranch.addWipPrefix(configuration).remote(configuration).Name
-	if currentBaseBranch.Is("master") {
+	if configuration.LegacyMobSession && currentBaseBranch.Is(legacyBaseBranch) {
 		// LEGACY
-		remoteBranchNoQualifier = "mob-session"
+		remoteBranchNoQualifier = legacyWipBranch
 	}
 
 	var result []string
@@ -723,6 +710,9 @@ func startJoinMobSession(configuration config.Configuration) {
 	if currentWipBranch.hasLocalBranch() && doBranchesDiverge(baseBranch.remote(configuration).Name, currentWipBranch.Name) {
 		say.Warning("Careful, your wip branch (" + currentWipBranch.Name + ") diverges from your main branch (" + baseBranch.remote(configuration).Name + ") !")
 	}
+	if currentWipBranch.hasLocalBranch() {
+		warnAboutSyncedSession(configuration, currentWipBranch)
+	}
 
 	git("checkout", "-B", currentWipBranch.Name, currentWipBranch.remote(configuration).Name)
 	git("branch", "--set-upstream-to="+currentWipBranch.remote(configuration).Name, currentWipBranch.Name)
@@ -733,7 +723,7 @@ func startNewMobSession(configuration config.Configuration) {
 
 	say.Info("starting new session from " + currentBaseBranch.remote(configuration).String())
 	git("checkout", "-B", currentWipBranch.Name, currentBaseBranch.remote(configuration).Name)
-	gitWithoutEmptyStrings(append(gitPushArgs(configuration), gitHooksOption(configuration), "--set-upstream", configuration.RemoteName, currentWipBranch.Name+":"+currentWipBranch.Name)...)
+	gitWithoutEmptyStrings(append(gitPushArgs(configuration), gitHooksOption(configuration), "--set-upstream", configuration.RemoteName, currentWipBranch.Name+":"+currentWipBranch.Name, shareBaseBranchRefspec(currentWipBranch, currentBaseBranch))...)
 }
 
 func gitPushArgs(c config.Configuration) []string {
@@ -776,14 +766,27 @@ func next(configuration config.Configuration) {
 
 	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 
-	if isNothingToCommit() {
+	var excluded []string
+	if configuration.NextInteractive && !isNothingToCommit() {
+		var err error
+		if excluded, err = askForExcludedFiles(); err != nil {
+			return
+		}
+	}
+
+	if isNothingToCommit() || !hasChangesToHandOver(excluded) {
+		if !isNothingToCommit() {
+			say.Info("all changes are kept in your working tree")
+		}
 		if currentWipBranch.hasLocalCommits(configuration) {
 			gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, currentWipBranch.Name)
 		} else {
 			say.Info("nothing was done, so nothing to commit")
 		}
+	} else if configuration.NextHandover {
+		nextHandover(configuration, currentWipBranch, excluded)
 	} else {
-		makeWipCommit(configuration)
+		makeWipCommitExcluding(configuration, excluded)
 		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, currentWipBranch.Name)
 	}
 	showNext(configuration)
@@ -801,20 +804,35 @@ func getCachedChanges() string {
 	return silentgit("diff", "--cached", "--stat")
 }
 
-func makeWipCommit(configuration config.Configuration) {
-	git("add", "--all")
+func makeWipCommit(configuration config.Configuration) bool {
+	return makeWipCommitExcluding(configuration, nil)
+}
+
+// commits all changes but the excluded ones and the ones matching .mobignore, returns false if there was nothing to commit
+func makeWipCommitExcluding(configuration config.Configuration, excluded []string) bool {
+	if !stageChangesToHandOver(excluded) {
+		return false
+	}
 	commitMessage := createWipCommitMessage(configuration)
-	gitWithoutEmptyStrings("commit", "--message", commitMessage, gitHooksOption(configuration))
+	gitWithoutEmptyStrings(append([]string{"commit", "--message", commitMessage, gitHooksOption(configuration)}, signingOptions(configuration)...)...)
 	say.InfoIndented(getChangesOfLastCommit())
 	say.InfoIndented(gitClient.CommitHash())
+	return true
 }
 
 func createWipCommitMessage(configuration config.Configuration) string {
 	commitMessage := configuration.WipCommitMessage
 
-	lastModifiedFilePath := getPathOfLastModifiedFile()
-	if lastModifiedFilePath != "" {
-		commitMessage += "\n\nlastFile:" + lastModifiedFilePath
+	trailers := ""
+	for _, trailer := range createWipCommitMetadata(configuration).trailers() {
+		trailers += trailer + "\n"
+	}
+	trailers += coauthors.Trailers(participantsAsCoauthors())
+	for _, trailer := range configuration.CommitTrailerLines() {
+		trailers += trailer + "\n"
+	}
+	if trailers != "" {
+		commitMessage += "\n\n" + strings.TrimSuffix(trailers, "\n")
 	}
 
 	return commitMessage
@@ -822,18 +840,29 @@ func createWipCommitMessage(configuration config.Configuration) string {
 
 // uses git status --porcelain. To work properly files have to be staged.
 func getPathOfLastModifiedFile() string {
+	files := getPathsOfModifiedFilesByRecency()
+	if len(files) == 0 {
+		return ""
+	}
+	return files[0]
+}
+
+// uses git status --porcelain. To work properly files have to be staged.
+func getPathsOfModifiedFilesByRecency() []string {
 	rootDir := gitRootDir()
 	files := getModifiedFiles(rootDir)
-	lastModifiedFilePath := ""
-	lastModifiedTime := time.Time{}
 
-	say.Debug("Find last modified file")
+	say.Debug("Find last modified files")
 	if len(files) == 1 {
-		lastModifiedFilePath = files[0]
-		say.Debug("Just one modified file: " + lastModifiedFilePath)
-		return lastModifiedFilePath
+		say.Debug("Just one modified file: " + files[0])
+		return files
 	}
 
+	type modifiedFile struct {
+		path    string
+		modTime time.Time
+	}
+	var modifiedFiles []modifiedFile
 	for _, file := range files {
 		unquotedFile := file
 		if strings.HasPrefix(file, "\"") {
@@ -853,14 +882,18 @@ func getPathOfLastModifiedFile() string {
 			say.Warning(err.Error())
 			continue
 		}
-		modTime := info.ModTime()
-		if modTime.After(lastModifiedTime) {
-			lastModifiedTime = modTime
-			lastModifiedFilePath = file
-		}
-		say.Debug(modTime.String())
+		modifiedFiles = append(modifiedFiles, modifiedFile{file, info.ModTime()})
+		say.Debug(info.ModTime().String())
 	}
-	return lastModifiedFilePath
+	sort.SliceStable(modifiedFiles, func(i, j int) bool {
+		return modifiedFiles[i].modTime.After(modifiedFiles[j].modTime)
+	})
+
+	paths := []string{}
+	for _, file := range modifiedFiles {
+		paths = append(paths, file.path)
+	}
+	return paths
 }
 
 // uses git status --porcelain. To work properly files have to be staged.
@@ -897,6 +930,11 @@ func fetch(configuration config.Configuration) {
 }
 
 func done(configuration config.Configuration) {
+	if isDoneInProgress(configuration) {
+		sayDoneInProgress(configuration)
+		return
+	}
+
 	if !isMobProgramming(configuration) {
 		say.Fix("to start working together, use", configuration.Mob("start"))
 		return
@@ -907,59 +945,95 @@ func done(configuration config.Configuration) {
 	baseBranch, wipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 
 	if wipBranch.hasRemoteBranch(configuration) {
-		if configuration.DoneSquash == config.SquashWip {
+		restoreHandover(configuration, wipBranch)
+		// collected before squashing, which drops the authors of wip commits
+		sessionCoauthors := collectSessionCoauthors(configuration, baseBranch, wipBranch)
+		if configuration.DoneSquash == config.SquashWip || configuration.DoneSquash == config.Rebase {
 			git("merge", "FETCH_HEAD", "--ff-only")
-			squashWip(configuration)
 		}
-		uncommittedChanges := hasUncommittedChanges()
-		if uncommittedChanges {
-			makeWipCommit(configuration)
+		beforeDone := stateBeforeDone(configuration, baseBranch, wipBranch)
+		if configuration.DoneSquash == config.SquashWip || configuration.DoneSquash == config.Rebase {
+			if err := squashWip(configuration); err != nil {
+				say.Error("Could not squash the wip commits of '" + wipBranch.String() + "', it is unchanged: " + err.Error())
+				say.Fix("To squash all commits into one final commit instead, use", configuration.Mob("done --squash"))
+				exit.Exit(1)
+				return
+			}
 		}
+		// the changes may all be kept because of .mobignore, then there is no wip commit to undo after the merge
+		madeWipCommit := hasUncommittedChanges() && makeWipCommit(configuration)
 		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, wipBranch.Name)
 
-		git("checkout", baseBranch.Name)
-		git("merge", baseBranch.remote(configuration).Name, "--ff-only")
-		mergeFailed := gitIgnoreFailure("merge", squashOrCommit(configuration), "--ff", wipBranch.Name)
-
-		if mergeFailed != nil {
-			// TODO should this be an error and a fix for that error?
-			say.Warning("Skipped deleting " + wipBranch.Name + " because of merge conflicts.")
-			say.Warning("To fix this, solve the merge conflict manually, commit, push, and afterwards delete " + wipBranch.Name)
+		if isStackedSession(baseBranch, configuration) {
+			doneStacked(configuration, baseBranch, wipBranch)
 			return
 		}
 
-		git("branch", "-D", wipBranch.Name)
-
-		if uncommittedChanges && configuration.DoneSquash != config.Squash { // give the user the chance to name their final commit
-			git("reset", "--soft", "HEAD^")
+		if configuration.DonePullRequest {
+			donePullRequest(configuration, baseBranch, wipBranch, sessionCoauthors)
+			return
 		}
 
-		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", wipBranch.Name)
+		if configuration.DoneSquash == config.Rebase {
+			doneRebase(configuration, baseBranch, wipBranch, sessionCoauthors, madeWipCommit)
+			return
+		}
 
-		cachedChanges := getCachedChanges()
-		hasCachedChanges := len(cachedChanges) > 0
-		if hasCachedChanges {
-			say.InfoIndented(cachedChanges)
+		git("checkout", baseBranch.Name)
+		git("merge", baseBranch.remote(configuration).Name, "--ff-only")
+		mergeArgs := []string{"merge", squashOrCommit(configuration), "--ff", wipBranch.Name}
+		if configuration.DoneSquash != config.Squash { // a merge commit may be created
+			mergeArgs = append(mergeArgs, signingOptions(configuration)...)
 		}
-		err := coauthors.AppendCoauthorsToSquashMsg(gitDir(), gitUserEmail())
-		if err != nil {
-			say.Warning(err.Error())
+		mergeFailed := gitIgnoreFailure(mergeArgs...)
+
+		if mergeFailed != nil {
+			sayDoneMergeConflicts(configuration, beforeDone)
+			return
 		}
 
-		if hasUncommittedChanges() {
-			say.Next("To finish, use", "git commit")
-		} else if configuration.DoneSquash == config.Squash {
-			say.Info("nothing was done, so nothing to commit")
+		if madeWipCommit && configuration.DoneSquash != config.Squash { // give the user the chance to name their final commit
+			git("reset", "--soft", "HEAD^")
 		}
 
+		finishDone(configuration, baseBranch, wipBranch, sessionCoauthors)
 	} else {
 		git("checkout", baseBranch.Name)
 		git("branch", "-D", wipBranch.Name)
 		git("pull", "--ff-only")
+		clearParticipants()
 		say.Info("someone else already ended your session")
 	}
 }
 
+// deletes the merged wip branch and leaves the final commit to the user
+func finishDone(configuration config.Configuration, baseBranch Branch, wipBranch Branch, sessionCoauthors []coauthors.Author) {
+	commitMessage, hasCommitMessageTemplate := doneCommitMessage(configuration, baseBranch, wipBranch, sessionCoauthors)
+	git("branch", "-D", wipBranch.Name)
+	gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", wipBranch.Name, sharedBaseBranchRefToDelete(wipBranch))
+	forgetBaseBranch(wipBranch)
+	clearParticipants()
+
+	cachedChanges := getCachedChanges()
+	hasCachedChanges := len(cachedChanges) > 0
+	if hasCachedChanges {
+		say.InfoIndented(cachedChanges)
+	}
+	if hasStagedChanges() {
+		if hasCommitMessageTemplate {
+			prefillCommitMessage(commitMessage)
+			appendFinalCommitTrailers(configuration, "SQUASH_MSG", nil)
+		} else {
+			appendFinalCommitTrailers(configuration, "SQUASH_MSG", sessionCoauthors)
+		}
+		say.Next("To finish, use", finalCommitCommand(configuration))
+	} else if configuration.DoneSquash == config.Squash {
+		say.Info("nothing was done, so nothing to commit")
+	} else {
+		finishWithLastCommit(configuration, baseBranch, sessionCoauthors, hasCommitMessageTemplate)
+	}
+}
+
 func gitDir() string {
 	return gitClient.Dir()
 }
@@ -1009,6 +1083,12 @@ func hasUncommittedChanges() bool {
 	return gitClient.HasUncommittedChanges()
 }
 
+// only the staged changes make a final commit, files kept by .mobignore stay modified after 'mob done'
+func hasStagedChanges() bool {
+	_, err := silentgitignorefailure("diff", "--cached", "--quiet")
+	return err != nil
+}
+
 func isMobProgramming(configuration config.Configuration) bool {
 	currentBranch := gitCurrentBranch()
 	_, currentWipBranch := determineBranches(currentBranch, gitBranches(), configuration)
@@ -1052,7 +1132,7 @@ func showNext(configuration config.Configuration) {
 	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 	commitsBaseWipBranch := currentBaseBranch.String() + ".." + currentWipBranch.String()
 
-	changes := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%an", "--abbrev-commit")
+	changes := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%an <%ae>", "--abbrev-commit")
 	lines := strings.Split(strings.Replace(changes, "\r\n", "\n", -1), "\n")
 	numberOfLines := len(lines)
 	say.Debug("there have been " + strconv.Itoa(numberOfLines) + " changes")
@@ -1060,7 +1140,9 @@ func showNext(configuration config.Configuration) {
 	if numberOfLines < 1 {
 		return
 	}
-	nextTypist, previousCommitters := findnext.FindNextTypist(lines, gitUserName)
+	// the same person may commit with different names from different machines
+	registry := readAuthorRegistry()
+	nextTypist, previousCommitters := findnext.FindNextTypist(findnext.CanonicalNames(lines, registry), coauthors.Name(registry.Canonical(currentAuthor())))
 	if nextTypist != "" {
 		if len(previousCommitters) != 0 {
 			say.Info("Committers after your last commit: " + strings.Join(previousCommitters, ", "))
@@ -118,6 +118,11 @@ func TestRemoveWipBranchQualifierWithoutBranchQualifierSet(t *testing.T) {
 	configuration.WipBranchQualifierSeparator = "-"
 	configuration.WipBranchQualifier = ""
 	equals(t, "master", newBranch("master-test-branch").removeWipQualifier([]string{}, configuration).Name)
+
+	configuration.WipBranchQualifierSeparator = "-"
+	configuration.WipBranchQualifier = ""
+	equals(t, "my-team/main", newBranch("my-team/main").removeWipQualifier([]string{}, configuration).Name)
+	equals(t, "main-", newBranch("main-").removeWipQualifier([]string{}, configuration).Name)
 }
 
 func TestVersion(t *testing.T) {
@@ -609,9 +614,10 @@ func TestBranch(t *testing.T) {
 	output, configuration := setup(t)
 	start(configuration)
 
-	branch(configuration)
+	branch(configuration, []string{})
 
-	assertOutputContains(t, output, "\norigin/mob-session\n")
+	assertOutputContains(t, output, "base branch 'master'")
+	assertOutputContains(t, output, "\n  origin/mob-session  ")
 }
 
 func TestStartIncludeUntrackedFiles(t *testing.T) {
@@ -810,7 +816,7 @@ func TestStartNextStay(t *testing.T) {
 
 	next(configuration)
 
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:file1.txt")
+	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:file1.txt\nMob-Last-File-Line: 1\nMob-Driver: local <local@example.com>\nMob-Rotation: 1")
 	assertOnBranch(t, "mob-session")
 }
 
@@ -823,7 +829,7 @@ func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFileIsAdded(t *testing.
 	createFile(t, "newerFile.txt", "contentIrrelevant")
 	next(configuration)
 
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:newerFile.txt")
+	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:newerFile.txt\nMob-Last-File-Line: 1\nMob-Recent-File: olderFile.txt\nMob-Driver: local <local@example.com>\nMob-Rotation: 1")
 }
 
 func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFileIsModified(t *testing.T) {
@@ -840,7 +846,7 @@ func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFileIsModified(t *testi
 	next(configuration)
 
 	assertOnBranch(t, "mob-session")
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:file1.txt")
+	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:file1.txt\nMob-Last-File-Line: 1\nMob-Driver: local <local@example.com>\nMob-Rotation: 2")
 }
 
 func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFileIsModifiedAndWorkingDirIsNotProjectRoot(t *testing.T) {
@@ -859,7 +865,7 @@ func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFileIsModifiedAndWorkin
 	next(configuration)
 
 	assertOnBranch(t, "mob-session")
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:file1.txt")
+	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:file1.txt\nMob-Last-File-Line: 1\nMob-Driver: local <local@example.com>\nMob-Rotation: 2")
 }
 
 func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFilenameContainsSpaces(t *testing.T) {
@@ -871,7 +877,7 @@ func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFilenameContainsSpaces(
 
 	next(configuration)
 
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:\"file with spaces.txt\"")
+	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:\"file with spaces.txt\"\nMob-Last-File-Line: 1\nMob-Driver: local <local@example.com>\nMob-Rotation: 1")
 	assertOnBranch(t, "mob-session")
 }
 
@@ -889,7 +895,7 @@ func TestStartNextStay_DoNotWriteLastModifiedFileInCommit_WhenFileIsDeleted(t *t
 	next(configuration)
 
 	assertOnBranch(t, "mob-session")
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage)
+	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nMob-Driver: local <local@example.com>\nMob-Rotation: 2")
 }
 
 func TestStartNextStay_DoNotWriteLastModifiedFileInCommit_WhenFileIsMoved(t *testing.T) {
@@ -906,7 +912,7 @@ func TestStartNextStay_DoNotWriteLastModifiedFileInCommit_WhenFileIsMoved(t *tes
 	next(configuration)
 
 	assertOnBranch(t, "mob-session")
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage)
+	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nMob-Driver: local <local@example.com>\nMob-Rotation: 2")
 }
 
 func TestStartNextStay_OpenLastModifiedFile(t *testing.T) {
@@ -1724,7 +1730,8 @@ func TestDoneMergeConflict(t *testing.T) {
 	setWorkingDir(tempDir + "/local")
 	start(configuration)
 	done(configuration)
-	assertOutputContains(t, output, "To fix this, solve the merge conflict manually, commit, push, and afterwards delete mob-session")
+	assertOutputContains(t, output, "Merging mob-session stopped because of conflicts in:")
+	assertOutputContains(t, output, "mob done --continue")
 }
 
 func TestDoneMerge(t *testing.T) {
@@ -2019,6 +2026,18 @@ func TestMobVersionWorksOutsideOfGitRepository(t *testing.T) {
 	assertOutputContains(t, output, "v"+versionNumber)
 }
 
+func TestMobFailsOnMisplacedOption(t *testing.T) {
+	output, _ := setup(t)
+	mockExit()
+	defer resetExit()
+
+	runMob(t, tempDir+"/local", "next", "--squash")
+
+	assertOutputContains(t, output, "option '--squash' is not supported by 'mob next', it is supported by: done, init")
+	assertOutputContains(t, output, "mob help")
+	assertOnBranch(t, "master")
+}
+
 func runMob(t *testing.T, workingDir string, args ...string) {
 	setWorkingDir(workingDir)
 	newArgs := append([]string{"mob"}, args...)
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/remotemobprogramming/mob/v5/input"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// files matching the patterns of the .mobignore file in the root of the repository (gitignore syntax) are never handed
+// over, they stay in the working tree of the driver
+const mobIgnoreFile = ".mobignore"
+
+type fileChange struct {
+	status string // as in 'git status --porcelain', e.g. " M" or "??"
+	path   string // relative to the root of the repository
+}
+
+// the changes in the index and working tree, including each untracked file
+func changedFiles() []fileChange {
+	output, err := silentgitignorefailure("status", "--porcelain=v2", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil
+	}
+	var changes []fileChange
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		fieldsBeforePath := map[byte]int{'1': 8, '2': 9, 'u': 10}
+		switch {
+		case strings.HasPrefix(entry, "? "):
+			changes = append(changes, fileChange{status: "??", path: entry[2:]})
+		case len(entry) > 4 && fieldsBeforePath[entry[0]] > 0:
+			fields := strings.SplitN(entry, " ", fieldsBeforePath[entry[0]]+1)
+			if len(fields) == fieldsBeforePath[entry[0]]+1 {
+				changes = append(changes, fileChange{status: strings.ReplaceAll(fields[1], ".", " "), path: fields[len(fields)-1]})
+			}
+			if entry[0] == '2' {
+				i++ // skip the original path of the rename
+			}
+		}
+	}
+	return changes
+}
+
+// the paths of the changes that match the .mobignore file
+func mobIgnoredFiles(changes []fileChange) []string {
+	rootDir := gitRootDir()
+	mobIgnorePath := rootDir + "/" + mobIgnoreFile
+	if _, err := os.Stat(mobIgnorePath); err != nil || len(changes) == 0 {
+		return nil
+	}
+	args := []string{"-c", "core.excludesFile=" + mobIgnorePath, "check-ignore", "--no-index", "--verbose", "--"}
+	for _, change := range changes {
+		args = append(args, rootDir+"/"+change.path)
+	}
+	output, err := silentgitignorefailure(args...)
+	if err != nil { // exits with 1 if no path is ignored
+		return nil
+	}
+	var ignored []string
+	for _, line := range strings.Split(output, "\n") {
+		source, path, found := strings.Cut(line, "\t")
+		if !found || !strings.HasPrefix(source, mobIgnorePath+":") {
+			continue // ignored by a .gitignore file
+		}
+		if _, pattern, _ := strings.Cut(strings.TrimPrefix(source, mobIgnorePath+":"), ":"); strings.HasPrefix(pattern, "!") {
+			continue // a negated pattern matched
+		}
+		ignored = append(ignored, strings.TrimPrefix(unquoteFilePath(path), rootDir+"/"))
+	}
+	return ignored
+}
+
+// the changes to hand over, without the ones matching .mobignore or excluded by the driver
+func changesToHandOver(excluded []string) (handOver []fileChange, kept []string) {
+	changes := changedFiles()
+	kept = append(mobIgnoredFiles(changes), excluded...)
+	for _, change := range changes {
+		if !stringContains(kept, change.path) {
+			handOver = append(handOver, change)
+		}
+	}
+	return handOver, kept
+}
+
+func topPathspecs(paths []string) []string {
+	pathspecs := make([]string, len(paths))
+	for i, path := range paths {
+		pathspecs[i] = ":(top)" + path
+	}
+	return pathspecs
+}
+
+func sayKeptFiles(kept []string) {
+	if len(kept) > 0 {
+		say.Info("not handed over, kept in your working tree:")
+		say.InfoIndented(strings.Join(kept, "\n"))
+	}
+}
+
+// stages all changes but the kept ones, returns false if nothing is staged
+func stageChangesToHandOver(excluded []string) bool {
+	handOver, kept := changesToHandOver(excluded)
+	git("add", "--all")
+	if len(kept) > 0 {
+		git(append([]string{"reset", "--quiet", "--"}, topPathspecs(kept)...)...)
+	}
+	sayKeptFiles(kept)
+	return len(handOver) > 0
+}
+
+// stashes all changes but the kept ones, returns false if there is nothing to stash
+func stashChangesToHandOver(message string, excluded []string) bool {
+	handOver, kept := changesToHandOver(excluded)
+	sayKeptFiles(kept)
+	if len(handOver) == 0 {
+		return false
+	}
+	paths := make([]string, len(handOver))
+	for i, change := range handOver {
+		paths[i] = change.path
+	}
+	git(append([]string{"stash", "push", "--include-untracked", "--message", message, "--"}, topPathspecs(paths)...)...)
+	return true
+}
+
+// asks the driver which of the changes to exclude from the handover
+func askForExcludedFiles() ([]string, error) {
+	handOver, _ := changesToHandOver(nil)
+	if len(handOver) == 0 {
+		return nil, nil
+	}
+	say.Info("changes to hand over:")
+	for i, change := range handOver {
+		say.WithPrefix(change.status+" "+change.path, "  "+strconv.Itoa(i+1)+") ")
+	}
+	answer := input.Ask("Which files do you want to exclude? (numbers separated by spaces, leave empty for none)", "")
+	var excluded []string
+	for _, number := range strings.Fields(strings.ReplaceAll(answer, ",", " ")) {
+		selected, err := strconv.Atoi(number)
+		if err != nil || selected < 1 || selected > len(handOver) {
+			say.Error("There is no file '" + number + "'.")
+			return nil, errors.New("no such file")
+		}
+		excluded = append(excluded, handOver[selected-1].path)
+	}
+	return excluded, nil
+}
+
+func hasChangesToHandOver(excluded []string) bool {
+	handOver, _ := changesToHandOver(excluded)
+	return len(handOver) > 0
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/workdir"
+)
+
+func TestNextKeepsMobIgnoredFiles(t *testing.T) {
+	output, configuration := setup(t)
+	createFileAndCommitIt(t, ".mobignore", "*.local\nscratch/\n", "add .mobignore")
+	git("push", "origin", "master")
+	start(configuration)
+	createFile(t, "settings.local", "contentIrrelevant")
+	os.Mkdir(filepath.Join(workdir.Path, "scratch"), 0755)
+	createFile(t, "scratch/notes.txt", "contentIrrelevant")
+	createFile(t, "example.txt", "contentIrrelevant")
+
+	next(configuration)
+
+	assertOutputContains(t, output, "not handed over, kept in your working tree:")
+	equals(t, "example.txt", silentgit("diff", "--name-only", "origin/master", "origin/mob-session"))
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{"settings.local": "??", "scratch/": "??"})
+}
+
+func TestNextKeepsModifiedMobIgnoredFile(t *testing.T) {
+	_, configuration := setup(t)
+	createFileAndCommitIt(t, ".mobignore", "config.txt\n", "add .mobignore")
+	createFileAndCommitIt(t, "config.txt", "v1", "add config")
+	git("push", "origin", "master")
+	start(configuration)
+	createFile(t, "config.txt", "v2")
+	createFile(t, "example.txt", "contentIrrelevant")
+
+	next(configuration)
+
+	equals(t, "v1", silentgit("show", "origin/mob-session:config.txt"))
+	equals(t, "contentIrrelevant", silentgit("show", "origin/mob-session:example.txt"))
+	assertGitStatus(t, GitStatus{"config.txt": "M"})
+}
+
+func TestNextHandsOverNegatedMobIgnoredFile(t *testing.T) {
+	_, configuration := setup(t)
+	createFileAndCommitIt(t, ".mobignore", "*.local\n!shared.local\n", "add .mobignore")
+	git("push", "origin", "master")
+	start(configuration)
+	createFile(t, "settings.local", "contentIrrelevant")
+	createFile(t, "shared.local", "contentIrrelevant")
+
+	next(configuration)
+
+	equals(t, "shared.local", silentgit("diff", "--name-only", "origin/master", "origin/mob-session"))
+	assertGitStatus(t, GitStatus{"settings.local": "??"})
+}
+
+func TestNextWithOnlyMobIgnoredFiles(t *testing.T) {
+	output, configuration := setup(t)
+	createFileAndCommitIt(t, ".mobignore", "*.local\n", "add .mobignore")
+	git("push", "origin", "master")
+	start(configuration)
+	wipCommit := silentgit("rev-parse", "HEAD")
+	createFile(t, "settings.local", "contentIrrelevant")
+
+	next(configuration)
+
+	assertOutputContains(t, output, "all changes are kept in your working tree")
+	equals(t, wipCommit, silentgit("rev-parse", "origin/mob-session"))
+	assertGitStatus(t, GitStatus{"settings.local": "??"})
+}
+
+func TestNextInteractiveExcludesFiles(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "a.txt", "contentIrrelevant")
+	createFile(t, "b.txt", "contentIrrelevant")
+	createFile(t, "c.txt", "contentIrrelevant")
+	configuration.NextInteractive = true
+	mockInteractiveInput(t, "1 3\n")
+
+	next(configuration)
+
+	assertOutputContains(t, output, "1) ?? a.txt")
+	assertOutputContains(t, output, "3) ?? c.txt")
+	equals(t, "b.txt", silentgit("diff", "--name-only", "origin/master", "origin/mob-session"))
+	assertGitStatus(t, GitStatus{"a.txt": "??", "c.txt": "??"})
+}
+
+func TestNextInteractiveWithInvalidAnswer(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	wipCommit := silentgit("rev-parse", "HEAD")
+	createFile(t, "a.txt", "contentIrrelevant")
+	configuration.NextInteractive = true
+	mockInteractiveInput(t, "2\n")
+
+	next(configuration)
+
+	assertOutputContains(t, output, "There is no file '2'.")
+	equals(t, wipCommit, silentgit("rev-parse", "origin/mob-session"))
+	assertOnBranch(t, "mob-session")
+	assertGitStatus(t, GitStatus{"a.txt": "??"})
+}
+
+func TestNextHandoverKeepsMobIgnoredFiles(t *testing.T) {
+	_, configuration := setup(t)
+	createFileAndCommitIt(t, ".mobignore", "*.local\n", "add .mobignore")
+	git("push", "origin", "master")
+	start(configuration)
+	createFile(t, "settings.local", "contentIrrelevant")
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.NextHandover = true
+
+	next(configuration)
+
+	handover := silentgit("ls-remote", "origin", "refs/mob/handover/mob-session")[:40]
+	git("fetch", "origin", "refs/mob/handover/mob-session")
+	equals(t, "example.txt", silentgit("ls-tree", "-r", "--name-only", handover+"^3"))
+	assertGitStatus(t, GitStatus{"settings.local": "??"})
+}
+
+func TestDoneKeepsMobIgnoredFiles(t *testing.T) {
+	_, configuration := setup(t)
+	createFileAndCommitIt(t, ".mobignore", "*.local\n", "add .mobignore")
+	git("push", "origin", "master")
+	start(configuration)
+	createFile(t, "settings.local", "contentIrrelevant")
+	createFile(t, "example.txt", "contentIrrelevant")
+
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{"example.txt": "A", "settings.local": "??"})
+}
+
+func TestStartIncludeUncommittedChangesKeepsMobIgnoredFiles(t *testing.T) {
+	output, configuration := setup(t)
+	createFileAndCommitIt(t, ".mobignore", "*.local\n", "add .mobignore")
+	git("push", "origin", "master")
+	createFile(t, "settings.local", "contentIrrelevant")
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.HandleUncommittedChanges = config.IncludeChanges
+
+	start(configuration)
+
+	assertOutputContains(t, output, "not handed over, kept in your working tree:")
+	assertOnBranch(t, "mob-session")
+	assertGitStatus(t, GitStatus{"settings.local": "??", "example.txt": "??"})
+	equals(t, "", silentgit("stash", "list"))
+	assertFileExist(t, filepath.Join(workdir.Path, "settings.local"))
+}
+
+func TestChangedFiles(t *testing.T) {
+	setup(t)
+	createFileAndCommitIt(t, "old.txt", "contentIrrelevant", "add old.txt")
+	createFileAndCommitIt(t, "tracked.txt", "v1", "add tracked.txt")
+	git("mv", "old.txt", "new name.txt")
+	createFile(t, "tracked.txt", "v2")
+	createFile(t, "subdir/untracked.txt", "contentIrrelevant")
+
+	changes := changedFiles()
+
+	equals(t, []fileChange{
+		{status: "R ", path: "new name.txt"},
+		{status: " M", path: "tracked.txt"},
+		{status: "??", path: "subdir/untracked.txt"},
+	}, changes)
+}
+
+func TestDoneNoSquashWithOnlyMobIgnoredFilesKeepsLastCommit(t *testing.T) {
+	output, configuration := setup(t)
+	createFileAndCommitIt(t, ".mobignore", "*.local\n", "add .mobignore")
+	git("push", "origin", "master")
+	start(configuration)
+	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "manual M1")
+	createFile(t, "settings.local", "contentIrrelevant")
+	configuration.DoneSquash = config.NoSquash
+
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	equals(t, "manual M1", silentgit("log", "-1", "--pretty=format:%s"))
+	assertGitStatus(t, GitStatus{"settings.local": "??"})
+	assertOutputNotContains(t, output, "To finish, use")
+}
+
+func TestDoneRebaseWithOnlyMobIgnoredFilesLeavesNothingToCommit(t *testing.T) {
+	output, configuration := setup(t)
+	createFileAndCommitIt(t, ".mobignore", "*.local\n", "add .mobignore")
+	git("push", "origin", "master")
+	start(configuration)
+	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "manual M1")
+	createFile(t, "settings.local", "contentIrrelevant")
+	configuration.DoneSquash = config.Rebase
+
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	equals(t, "manual M1", silentgit("log", "-1", "--pretty=format:%s"))
+	assertGitStatus(t, GitStatus{"settings.local": "??"})
+	assertOutputNotContains(t, output, "To finish, use")
+	assertOutputContains(t, output, "To publish the rebased commits, use")
+}
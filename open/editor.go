@@ -0,0 +1,137 @@
+package open
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Location is a file to open at a line, lines start at 1
+type Location struct {
+	Path string
+	Line int
+}
+
+type editor int
+
+const (
+	unknownEditor editor = iota
+	vsCode
+	jetBrains
+	vim
+	emacs
+)
+
+var editors = map[string]editor{
+	"code":          vsCode,
+	"code-insiders": vsCode,
+	"codium":        vsCode,
+	"vscodium":      vsCode,
+	"cursor":        vsCode,
+	"idea":          jetBrains,
+	"idea64":        jetBrains,
+	"webstorm":      jetBrains,
+	"pycharm":       jetBrains,
+	"goland":        jetBrains,
+	"clion":         jetBrains,
+	"phpstorm":      jetBrains,
+	"rubymine":      jetBrains,
+	"rider":         jetBrains,
+	"rustrover":     jetBrains,
+	"studio":        jetBrains,
+	"vi":            vim,
+	"vim":           vim,
+	"nvim":          vim,
+	"gvim":          vim,
+	"mvim":          vim,
+	"emacs":         emacs,
+	"emacsclient":   emacs,
+}
+
+// EditorCommands returns the command lines to open the files with the open command, e.g. "idea %s". Known editors get
+// their own syntax for lines, other commands and commands with placeholders within a field, e.g. "--file=%s", are run
+// once per file with %s replaced by the path and %l by the line.
+func EditorCommands(openCommand string, locations []Location) [][]string {
+	fields := strings.Fields(openCommand)
+	if len(fields) == 0 || len(locations) == 0 {
+		return [][]string{}
+	}
+	name := fields[0]
+	var options []string
+	for _, field := range fields[1:] {
+		if field == "%s" {
+			continue
+		}
+		if strings.Contains(field, "%s") {
+			return commandsPerFile(openCommand, locations)
+		}
+		options = append(options, field)
+	}
+
+	if strings.Contains(openCommand, "%l") {
+		return commandsPerFile(openCommand, locations)
+	}
+	switch editorOf(name) {
+	case vsCode:
+		// the files get their lines with --goto, whether the open command has it or not
+		options = slices.DeleteFunc(options, func(option string) bool { return option == "--goto" || option == "-g" })
+		command := append([]string{name}, options...)
+		command = append(command, "--goto")
+		for _, location := range locations {
+			command = append(command, location.Path+":"+line(location))
+		}
+		return [][]string{command}
+	case jetBrains:
+		var commands [][]string
+		for _, location := range locations {
+			command := append([]string{name}, options...)
+			commands = append(commands, append(command, "--line", line(location), location.Path))
+		}
+		return commands
+	case vim:
+		// vim jumps to the line of the first file only
+		command := append([]string{name}, options...)
+		command = append(command, "+"+line(locations[0]))
+		for _, location := range locations {
+			command = append(command, location.Path)
+		}
+		return [][]string{command}
+	case emacs:
+		command := append([]string{name}, options...)
+		for _, location := range locations {
+			command = append(command, "+"+line(location), location.Path)
+		}
+		return [][]string{command}
+	}
+	return commandsPerFile(openCommand, locations)
+}
+
+func commandsPerFile(openCommand string, locations []Location) [][]string {
+	var commands [][]string
+	for _, location := range locations {
+		command := strings.ReplaceAll(openCommand, "%l", line(location))
+		if !strings.Contains(command, "%s") {
+			command += " %s"
+		}
+		// split before inserting the path, so that paths may contain spaces
+		var fields []string
+		for _, field := range strings.Fields(command) {
+			fields = append(fields, strings.ReplaceAll(field, "%s", location.Path))
+		}
+		commands = append(commands, fields)
+	}
+	return commands
+}
+
+func editorOf(name string) editor {
+	base := strings.ToLower(filepath.Base(name))
+	for _, extension := range []string{".exe", ".cmd", ".bat", ".sh"} {
+		base = strings.TrimSuffix(base, extension)
+	}
+	return editors[base]
+}
+
+func line(location Location) string {
+	return strconv.Itoa(max(location.Line, 1))
+}
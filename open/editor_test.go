@@ -0,0 +1,57 @@
+package open
+
+import (
+	"testing"
+
+	"github.com/remotemobprogramming/mob/v5/test"
+)
+
+var locations = []Location{{Path: "/repo/src/main.go", Line: 12}, {Path: "/repo/src/main test.go", Line: 0}}
+
+func TestEditorCommandsVsCode(t *testing.T) {
+	test.Equals(t, [][]string{{"code", "-r", "--goto", "/repo/src/main.go:12", "/repo/src/main test.go:1"}}, EditorCommands("code -r %s", locations))
+}
+
+func TestEditorCommandsVsCodeWithGoto(t *testing.T) {
+	test.Equals(t, [][]string{{"code", "--goto", "/repo/src/main.go:12", "/repo/src/main test.go:1"}}, EditorCommands("code --goto %s", locations))
+}
+
+func TestEditorCommandsJetBrains(t *testing.T) {
+	test.Equals(t, [][]string{
+		{"idea", "--line", "12", "/repo/src/main.go"},
+		{"idea", "--line", "1", "/repo/src/main test.go"},
+	}, EditorCommands("idea %s", locations))
+	test.Equals(t, [][]string{{"/opt/goland/bin/goland.sh", "--line", "12", "/repo/src/main.go"}}, EditorCommands("/opt/goland/bin/goland.sh", locations[:1]))
+}
+
+func TestEditorCommandsVim(t *testing.T) {
+	test.Equals(t, [][]string{{"gvim", "-p", "+12", "/repo/src/main.go", "/repo/src/main test.go"}}, EditorCommands("gvim -p", locations))
+}
+
+func TestEditorCommandsEmacs(t *testing.T) {
+	test.Equals(t, [][]string{{"emacsclient", "-n", "+12", "/repo/src/main.go", "+1", "/repo/src/main test.go"}}, EditorCommands("emacsclient -n %s", locations))
+}
+
+func TestEditorCommandsWithPlaceholders(t *testing.T) {
+	test.Equals(t, [][]string{
+		{"subl", "/repo/src/main.go:12"},
+		{"subl", "/repo/src/main test.go:1"},
+	}, EditorCommands("subl %s:%l", locations))
+	test.Equals(t, [][]string{{"code", "--goto", "/repo/src/main.go:12"}}, EditorCommands("code --goto %s:%l", locations[:1]))
+}
+
+func TestEditorCommandsWithPlaceholderInOption(t *testing.T) {
+	test.Equals(t, [][]string{
+		{"idea", "--file=/repo/src/main.go"},
+		{"idea", "--file=/repo/src/main test.go"},
+	}, EditorCommands("idea --file=%s", locations))
+}
+
+func TestEditorCommandsOfUnknownCommand(t *testing.T) {
+	test.Equals(t, [][]string{{"touch", "/repo/src/main test.go-1"}}, EditorCommands("touch %s-1", locations[1:]))
+	test.Equals(t, [][]string{{"open", "/repo/src/main.go"}}, EditorCommands("open", locations[:1]))
+}
+
+func TestEditorCommandsWithoutFiles(t *testing.T) {
+	test.Equals(t, [][]string{}, EditorCommands("idea %s", []Location{}))
+}
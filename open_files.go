@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/open"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+func openLastModifiedFilesIfPresent(configuration config.Configuration) {
+	if !configuration.IsOpenCommandGiven() {
+		say.Debug("No open command given")
+		return
+	}
+
+	say.Debug("Try to open last modified files")
+	if !lastCommitIsWipCommit(configuration) {
+		say.Debug("Last commit isn't a WIP commit.")
+		return
+	}
+	lastCommitMessage := lastCommitMessage()
+	if strings.Count(lastCommitMessage, lastFileTrailer) > 1 {
+		say.Warning("Could not determine last modified file from commit message, separator was used multiple times!")
+		return
+	}
+	metadata := parseWipCommitMetadata(lastCommitMessage)
+	if !strings.Contains(lastCommitMessage, lastFileTrailer) && !configuration.OpenAllFiles() {
+		say.Warning("Couldn't find last modified file in commit message!")
+		return
+	}
+	locations := filesToOpen(configuration, metadata)
+	if len(locations) == 0 {
+		say.Debug("Could not find last modified files in commit message")
+		return
+	}
+
+	for _, command := range open.EditorCommands(configuration.OpenCommand, locations) {
+		_, err := startCommand(command[0], command[1:]...)
+		if err != nil {
+			say.Warning(fmt.Sprintf("Couldn't open last modified file on your system (%s)", runtime.GOOS))
+			say.Warning(err.Error())
+			return
+		}
+	}
+	for _, location := range locations {
+		say.Debug(fmt.Sprintf("Open last modified file: %s:%d", location.Path, location.Line))
+	}
+}
+
+// the most recently modified files of the last wip commit first, limited by MOB_OPEN_FILES
+func filesToOpen(configuration config.Configuration, metadata WipCommitMetadata) []open.Location {
+	var files []string
+	for _, file := range append([]string{metadata.LastFile}, metadata.RecentFiles...) {
+		if path := unquoteFilePath(file); path != "" {
+			files = append(files, path)
+		}
+	}
+	if configuration.OpenAllFiles() {
+		for _, file := range filesOfLastCommit() {
+			if !stringContains(files, file) {
+				files = append(files, file)
+			}
+		}
+	} else if len(files) > configuration.OpenFilesLimit() {
+		files = files[:configuration.OpenFilesLimit()]
+	}
+
+	rootDir := gitRootDir()
+	locations := []open.Location{}
+	for i, file := range files {
+		line := 0
+		if i == 0 && file == metadata.lastFilePath() {
+			line = metadata.LastFileLine
+		}
+		if line == 0 {
+			line = firstChangedLineOfLastCommit(file)
+		}
+		locations = append(locations, open.Location{Path: rootDir + "/" + file, Line: line})
+	}
+	return locations
+}
+
+// added or modified files of the last commit, relative to the root of the repository
+func filesOfLastCommit() []string {
+	output, err := silentgitignorefailure("-C", gitRootDir(), "diff-tree", "--root", "--no-commit-id", "-r", "--name-only", "-z", "--diff-filter=AM", "HEAD")
+	if err != nil {
+		return []string{}
+	}
+	files := []string{}
+	for _, file := range strings.Split(output, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func firstChangedLineOfLastCommit(file string) int {
+	diff, err := silentgitignorefailure("-C", gitRootDir(), "show", "--format=", "--unified=0", "--no-color", "HEAD", "--", file)
+	if err != nil {
+		return 0
+	}
+	return firstLineOfDiff(diff)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/remotemobprogramming/mob/v5/open"
+)
+
+func TestFilesToOpenMostRecentlyModifiedFirst(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.OpenFiles = "2"
+
+	start(configuration)
+	createFileModifiedAt(t, "test.txt", "one\ntwo\n", time.Now().Add(-2*time.Minute))
+	createFileModifiedAt(t, "impl.txt", "one\n", time.Now().Add(-1*time.Minute))
+	createFileModifiedAt(t, "unrelated.txt", "one\n", time.Now().Add(-3*time.Minute))
+	next(configuration)
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+
+	equals(t, []open.Location{
+		{Path: tempDir + "/alice/impl.txt", Line: 1},
+		{Path: tempDir + "/alice/test.txt", Line: 1},
+	}, filesToOpen(configuration, parseWipCommitMetadata(lastCommitMessage())))
+}
+
+func TestFilesToOpenAllFilesOfLastWipCommit(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.OpenFiles = "all"
+
+	start(configuration)
+	createFile(t, "test.txt", "one\ntwo\n")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "test.txt", "one\ntwo\nthree\n")
+	createFile(t, "impl.txt", "one\n")
+	next(configuration)
+	// wip commits of older versions only know the last file
+	setWorkingDir(tempDir + "/bob")
+	start(configuration)
+	git("commit", "--amend", "--message", configuration.WipCommitMessage+"\n\nlastFile:impl.txt")
+
+	equals(t, []open.Location{
+		{Path: tempDir + "/bob/impl.txt", Line: 1},
+		{Path: tempDir + "/bob/test.txt", Line: 3},
+	}, filesToOpen(configuration, parseWipCommitMetadata(lastCommitMessage())))
+}
+
+func TestFilesToOpenWithoutLastFile(t *testing.T) {
+	_, configuration := setup(t)
+
+	start(configuration)
+	createFile(t, "file1.txt", "one\n")
+	next(configuration)
+	start(configuration)
+	removeFile(t, tempDir+"/local/file1.txt")
+	next(configuration)
+
+	start(configuration)
+
+	equals(t, []open.Location{}, filesToOpen(configuration, parseWipCommitMetadata(lastCommitMessage())))
+}
+
+func createFileModifiedAt(t *testing.T, filename string, content string, modTime time.Time) {
+	path := createFile(t, filename, content)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		failWithFailure(t, "changing the modification time of "+filename, err.Error())
+	}
+}
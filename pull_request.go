@@ -0,0 +1,129 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/remotemobprogramming/mob/v5/coauthors"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/pullrequest"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// pushes the squashed wip branch as a review branch and opens a pull request into the base branch instead of merging locally
+func donePullRequest(configuration config.Configuration, baseBranch Branch, wipBranch Branch, sessionCoauthors []coauthors.Author) {
+	reviewBranch := reviewBranchFor(configuration, baseBranch, wipBranch)
+	commitsBaseWipBranch := baseBranch.remote(configuration).Name + ".." + wipBranch.Name
+	title := pullRequestTitle(configuration, commitsBaseWipBranch, wipBranch)
+	description := pullRequestDescription(configuration, commitsBaseWipBranch, sessionCoauthors)
+
+	commitMessage, hasCommitMessageTemplate := doneCommitMessage(configuration, baseBranch, wipBranch, sessionCoauthors)
+	if !hasCommitMessageTemplate {
+		commitMessage = appendTrailers(title, coauthors.TrailerLines(sessionCoauthors))
+	}
+	commitMessage = appendTrailers(commitMessage, configuration.CommitTrailerLines())
+
+	git("checkout", "-b", reviewBranch.Name)
+	if !prepareReviewBranch(configuration, baseBranch, wipBranch, reviewBranch, commitMessage) {
+		return
+	}
+	hasChanges := silentgit("rev-list", "--count", baseBranch.remote(configuration).Name+"..HEAD") != "0"
+	if hasChanges {
+		say.Info("pushing review branch '" + reviewBranch.Name + "'")
+		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, reviewBranch.Name)
+	}
+
+	git("checkout", baseBranch.Name)
+	git("merge", baseBranch.remote(configuration).Name, "--ff-only")
+	git("branch", "-D", reviewBranch.Name)
+	git("branch", "-D", wipBranch.Name)
+	gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", wipBranch.Name, sharedBaseBranchRefToDelete(wipBranch))
+	forgetBaseBranch(wipBranch)
+	clearParticipants()
+
+	if !hasChanges {
+		say.Info("nothing was done, so there is nothing to review")
+		return
+	}
+
+	remoteUrl := silentgit("config", "--get", "remote."+configuration.RemoteName+".url")
+	result, err := pullrequest.Open(configuration, remoteUrl, pullrequest.PullRequest{
+		Title:        title,
+		Description:  description,
+		SourceBranch: reviewBranch.Name,
+		TargetBranch: baseBranch.Name,
+	})
+	if err != nil {
+		say.Warning(err.Error())
+	}
+	if result.Created {
+		say.Info("opened pull request " + result.Url)
+	} else if result.Url != "" {
+		say.Next("To open the pull request, visit", result.Url)
+	} else {
+		say.Fix("To open the pull request, create it from '"+reviewBranch.Name+"' into '"+baseBranch.Name+"' on your git hosting service, or set", "MOB_PULL_REQUEST_PROVIDER")
+	}
+}
+
+// applies MOB_DONE_SQUASH to the review branch, returns false if the review branch could not be prepared
+func prepareReviewBranch(configuration config.Configuration, baseBranch Branch, wipBranch Branch, reviewBranch Branch, commitMessage string) bool {
+	switch configuration.DoneSquash {
+	case config.Squash:
+		git("reset", "--soft", silentgit("merge-base", "HEAD", baseBranch.remote(configuration).Name))
+		if len(getCachedChanges()) > 0 {
+			gitWithoutEmptyStrings(append([]string{"commit", "--message", commitMessage, gitHooksOption(configuration)}, signingOptions(configuration)...)...)
+		}
+	case config.SquashWip, config.Rebase:
+		if configuration.DoneSquash == config.Rebase {
+			if err := gitIgnoreFailure(rebaseArgs(configuration, baseBranch)...); err != nil {
+				git("rebase", "--abort")
+				git("checkout", wipBranch.Name)
+				git("branch", "-D", reviewBranch.Name)
+				say.Error("Could not rebase '" + wipBranch.Name + "' onto '" + baseBranch.remote(configuration).Name + "' because of conflicts.")
+				say.Fix("To open the pull request without rebasing, use", configuration.Mob("done --pr --squash-wip"))
+				return false
+			}
+		}
+		if lastCommitIsWipCommit(configuration) {
+			gitWithoutEmptyStrings(append([]string{"commit", "--amend", "--message", commitMessage, gitHooksOption(configuration)}, signingOptions(configuration)...)...)
+		}
+	}
+	return true
+}
+
+// the review branch is named after the wip branch, e.g. 'review/main-green' for 'mob/main-green'
+func reviewBranchFor(configuration config.Configuration, baseBranch Branch, wipBranch Branch) Branch {
+	name := "review/" + baseBranch.Name
+	if strings.HasPrefix(wipBranch.Name, configuration.WipBranchPrefix) {
+		name = "review/" + wipBranch.removeWipPrefix(configuration).Name
+	}
+
+	existingBranches := append(gitBranches(), gitRemoteBranches()...)
+	reviewBranch := newBranch(name)
+	for i := 2; reviewBranch.exists(existingBranches) || reviewBranch.remote(configuration).exists(existingBranches); i++ {
+		reviewBranch = newBranch(name + "-" + strconv.Itoa(i))
+	}
+	return reviewBranch
+}
+
+func pullRequestTitle(configuration config.Configuration, commits string, wipBranch Branch) string {
+	subjects := manualCommitSubjects(configuration, commits)
+	if len(subjects) > 0 {
+		return subjects[0]
+	}
+	return "Changes from mob session '" + wipBranch.Name + "'"
+}
+
+func pullRequestDescription(configuration config.Configuration, commits string, sessionCoauthors []coauthors.Author) string {
+	description := ""
+	for _, subject := range manualCommitSubjects(configuration, commits) {
+		description += "- " + subject + "\n"
+	}
+	if len(sessionCoauthors) > 0 {
+		if description != "" {
+			description += "\n"
+		}
+		description += coauthors.Trailers(sessionCoauthors)
+	}
+	return strings.TrimSuffix(description, "\n")
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+// the remote looks like a GitHub repository to mob, while git still pushes to the local remote directory
+func useFakeGitHubRemote(t *testing.T, configuration *config.Configuration) *map[string]string {
+	t.Helper()
+	requestBody := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &requestBody)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"html_url": "https://github.com/owner/repo/pull/1"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	git("config", "url."+getRemoteDirectory(tempDir)+".insteadOf", "https://github.com/owner/repo.git")
+	git("remote", "set-url", "origin", "https://github.com/owner/repo.git")
+	t.Setenv("MOB_PULL_REQUEST_TOKEN", "secret")
+	configuration.PullRequestApiUrl = server.URL
+	configuration.DonePullRequest = true
+	return &requestBody
+}
+
+func TestDonePullRequestSquash(t *testing.T) {
+	output, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "alice.txt", "alice")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	requestBody := useFakeGitHubRemote(t, &configuration)
+	start(configuration)
+	createFileAndCommitIt(t, "local.txt", "local", "add greeting")
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	assertGitStatus(t, GitStatus{})
+	equals(t, []string{"master"}, gitBranches())
+	assertCommitsOnBranch(t, 2, "origin/review/master")
+	equals(t, "add greeting\n\nCo-authored-by: alice <alice@example.com>", silentgit("log", "-1", "--pretty=format:%B", "origin/review/master"))
+	assertCommitsOnBranch(t, 1, "origin/master")
+	equals(t, map[string]string{
+		"title": "add greeting",
+		"body":  "- add greeting\n\nCo-authored-by: alice <alice@example.com>",
+		"head":  "review/master",
+		"base":  "master",
+	}, *requestBody)
+	assertOutputContains(t, output, "opened pull request https://github.com/owner/repo/pull/1")
+}
+
+func TestDonePullRequestNoSquashKeepsCommits(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.NoSquash
+
+	setWorkingDir(tempDir + "/local")
+	useFakeGitHubRemote(t, &configuration)
+	start(configuration)
+	createFileAndCommitIt(t, "local.txt", "local", "add greeting")
+	createFile(t, "wip.txt", "wip")
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertCommitsOnBranch(t, 3, "origin/review/master")
+	assertCommitLogContainsMessage(t, "origin/review/master", configuration.WipCommitMessage)
+}
+
+func TestDonePullRequestSquashWipNamesFinalCommit(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.SquashWip
+
+	setWorkingDir(tempDir + "/local")
+	useFakeGitHubRemote(t, &configuration)
+	start(configuration)
+	createFileAndCommitIt(t, "local.txt", "local", "add greeting")
+	createFile(t, "wip.txt", "wip")
+	done(configuration)
+
+	assertCommitsOnBranch(t, 3, "origin/review/master")
+	assertCommitLogNotContainsMessage(t, "origin/review/master", configuration.WipCommitMessage)
+	equals(t, "add greeting", silentgit("log", "-1", "--pretty=format:%s", "origin/review/master"))
+}
+
+func TestDonePullRequestUsesUniqueReviewBranch(t *testing.T) {
+	_, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/local")
+	useFakeGitHubRemote(t, &configuration)
+	git("push", "origin", "master:review/master")
+	start(configuration)
+	createFile(t, "local.txt", "local")
+	done(configuration)
+
+	assertCommitsOnBranch(t, 2, "origin/review/master-2")
+}
+
+func TestDonePullRequestWithoutTokenShowsUrl(t *testing.T) {
+	output, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/local")
+	useFakeGitHubRemote(t, &configuration)
+	t.Setenv("MOB_PULL_REQUEST_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	start(configuration)
+	createFile(t, "local.txt", "local")
+	done(configuration)
+
+	assertOutputContains(t, output, "https://github.com/owner/repo/compare/master...review/master?")
+}
+
+func TestDonePullRequestWithoutChanges(t *testing.T) {
+	output, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/local")
+	useFakeGitHubRemote(t, &configuration)
+	start(configuration)
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	equals(t, []string{"origin/master"}, gitRemoteBranches())
+	assertOutputContains(t, output, "nothing was done, so there is nothing to review")
+}
@@ -0,0 +1,44 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/remotemobprogramming/mob/v5/httpclient"
+)
+
+// GiteaProvider is a Provider implementation that creates pull requests with the Gitea (and Forgejo) REST api.
+type GiteaProvider struct {
+	repository Repository
+	apiUrl     string
+	token      string
+}
+
+func NewGiteaProvider(repository Repository, apiUrl string, token string) GiteaProvider {
+	return GiteaProvider{
+		repository: repository,
+		apiUrl:     apiUrlOrDefault(apiUrl, "https://"+repository.Host+"/api/v1"),
+		token:      token,
+	}
+}
+
+func (p GiteaProvider) IsActive() bool {
+	return p.token != ""
+}
+
+func (p GiteaProvider) Open(pullRequest PullRequest) (Result, error) {
+	requestBody, _ := json.Marshal(map[string]string{
+		"title": pullRequest.Title,
+		"body":  pullRequest.Description,
+		"head":  pullRequest.SourceBranch,
+		"base":  pullRequest.TargetBranch,
+	})
+	client := httpclient.CreateHttpClient(false)
+	responseBody, err := client.SendRequestWithHeaders(requestBody, "POST", p.apiUrl+"/repos/"+p.repository.Path+"/pulls", map[string]string{
+		"Authorization": "token " + p.token,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("pull request couldn't be created: %w", err)
+	}
+	return resultFromResponse(responseBody, "html_url")
+}
@@ -0,0 +1,49 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/remotemobprogramming/mob/v5/httpclient"
+)
+
+// GitHubProvider is a Provider implementation that creates pull requests with the GitHub REST api.
+type GitHubProvider struct {
+	repository Repository
+	apiUrl     string
+	token      string
+}
+
+func NewGitHubProvider(repository Repository, apiUrl string, token string) GitHubProvider {
+	defaultApiUrl := "https://" + repository.Host + "/api/v3" // GitHub Enterprise Server
+	if repository.Host == "github.com" {
+		defaultApiUrl = "https://api.github.com"
+	}
+	return GitHubProvider{
+		repository: repository,
+		apiUrl:     apiUrlOrDefault(apiUrl, defaultApiUrl),
+		token:      token,
+	}
+}
+
+func (p GitHubProvider) IsActive() bool {
+	return p.token != ""
+}
+
+func (p GitHubProvider) Open(pullRequest PullRequest) (Result, error) {
+	requestBody, _ := json.Marshal(map[string]string{
+		"title": pullRequest.Title,
+		"body":  pullRequest.Description,
+		"head":  pullRequest.SourceBranch,
+		"base":  pullRequest.TargetBranch,
+	})
+	client := httpclient.CreateHttpClient(false)
+	responseBody, err := client.SendRequestWithHeaders(requestBody, "POST", p.apiUrl+"/repos/"+p.repository.Path+"/pulls", map[string]string{
+		"Accept":        "application/vnd.github+json",
+		"Authorization": "Bearer " + p.token,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("pull request couldn't be created: %w", err)
+	}
+	return resultFromResponse(responseBody, "html_url")
+}
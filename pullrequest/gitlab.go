@@ -0,0 +1,45 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/remotemobprogramming/mob/v5/httpclient"
+)
+
+// GitLabProvider is a Provider implementation that creates merge requests with the GitLab REST api.
+type GitLabProvider struct {
+	repository Repository
+	apiUrl     string
+	token      string
+}
+
+func NewGitLabProvider(repository Repository, apiUrl string, token string) GitLabProvider {
+	return GitLabProvider{
+		repository: repository,
+		apiUrl:     apiUrlOrDefault(apiUrl, "https://"+repository.Host+"/api/v4"),
+		token:      token,
+	}
+}
+
+func (p GitLabProvider) IsActive() bool {
+	return p.token != ""
+}
+
+func (p GitLabProvider) Open(pullRequest PullRequest) (Result, error) {
+	requestBody, _ := json.Marshal(map[string]string{
+		"title":         pullRequest.Title,
+		"description":   pullRequest.Description,
+		"source_branch": pullRequest.SourceBranch,
+		"target_branch": pullRequest.TargetBranch,
+	})
+	client := httpclient.CreateHttpClient(false)
+	responseBody, err := client.SendRequestWithHeaders(requestBody, "POST", p.apiUrl+"/projects/"+url.PathEscape(p.repository.Path)+"/merge_requests", map[string]string{
+		"PRIVATE-TOKEN": p.token,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("merge request couldn't be created: %w", err)
+	}
+	return resultFromResponse(responseBody, "web_url")
+}
@@ -0,0 +1,158 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+	Gitea  = "gitea"
+)
+
+// PullRequest describes a pull request (GitHub, Gitea) or merge request (GitLab) from SourceBranch into TargetBranch.
+type PullRequest struct {
+	Title        string
+	Description  string
+	SourceBranch string
+	TargetBranch string
+}
+
+// Result points to the created pull request or, if it could not be created, to the page to create it manually.
+type Result struct {
+	Url     string
+	Created bool
+}
+
+// Provider abstracts the git hosting service so different implementations can be used.
+type Provider interface {
+	IsActive() bool
+	Open(pullRequest PullRequest) (Result, error)
+}
+
+// Repository is a repository on a git hosting service, e.g. Host "github.com" and Path "remotemobprogramming/mob".
+type Repository struct {
+	Host string
+	Path string
+}
+
+func (r Repository) webUrl() string {
+	return "https://" + r.Host + "/" + r.Path
+}
+
+var scpLikeUrl = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]{2,}):(.+)$`)
+
+// ParseRemoteUrl reads the repository from https, ssh and scp-like remote urls (git@github.com:owner/repo.git).
+func ParseRemoteUrl(remoteUrl string) (Repository, bool) {
+	remoteUrl = strings.TrimSpace(remoteUrl)
+	host, path := "", ""
+	if strings.Contains(remoteUrl, "://") {
+		parsed, err := url.Parse(remoteUrl)
+		if err != nil {
+			return Repository{}, false
+		}
+		switch parsed.Scheme {
+		case "http", "https":
+			host = parsed.Host
+		case "ssh", "git":
+			host = parsed.Hostname()
+		default:
+			return Repository{}, false
+		}
+		path = parsed.Path
+	} else if matches := scpLikeUrl.FindStringSubmatch(remoteUrl); matches != nil {
+		host, path = matches[1], matches[2]
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || !strings.Contains(path, "/") {
+		return Repository{}, false
+	}
+	return Repository{Host: host, Path: path}, true
+}
+
+// DetectProvider guesses the provider from the host of the repository, self-hosted instances need MOB_PULL_REQUEST_PROVIDER.
+func DetectProvider(repository Repository) string {
+	host := strings.ToLower(repository.Host)
+	switch {
+	case strings.Contains(host, "github"):
+		return GitHub
+	case strings.Contains(host, "gitlab"):
+		return GitLab
+	case strings.Contains(host, "gitea"), strings.Contains(host, "codeberg"):
+		return Gitea
+	}
+	return ""
+}
+
+func buildProviders(provider string, repository Repository, apiUrl string, token string) []Provider {
+	switch provider {
+	case GitHub:
+		return []Provider{NewGitHubProvider(repository, apiUrl, token), NewUrlProvider(provider, repository)}
+	case GitLab:
+		return []Provider{NewGitLabProvider(repository, apiUrl, token), NewUrlProvider(provider, repository)}
+	case Gitea:
+		return []Provider{NewGiteaProvider(repository, apiUrl, token), NewUrlProvider(provider, repository)}
+	}
+	return []Provider{NewUrlProvider(provider, repository)}
+}
+
+// Open creates the pull request with the first active provider. If the provider's api fails, the url to create the
+// pull request manually is returned together with the error. Unknown providers only get the generic compare url.
+func Open(configuration config.Configuration, remoteUrl string, pullRequest PullRequest) (Result, error) {
+	repository, found := ParseRemoteUrl(remoteUrl)
+	if !found {
+		return Result{}, errors.New("could not read the repository from the remote url '" + remoteUrl + "'")
+	}
+	provider := configuration.PullRequestProvider
+	if provider == "" {
+		provider = DetectProvider(repository)
+	}
+	say.Debug("Using pull request provider '" + provider + "' for " + repository.Host + "/" + repository.Path)
+
+	var openErr error
+	for _, p := range buildProviders(provider, repository, configuration.PullRequestApiUrl, token(provider)) {
+		if !p.IsActive() {
+			continue
+		}
+		result, err := p.Open(pullRequest)
+		if err == nil {
+			return result, openErr
+		}
+		say.Debug(fmt.Sprintf("%T failed: %s", p, err.Error()))
+		openErr = err
+	}
+	return Result{}, openErr
+}
+
+// the token is only read from the environment, so that it does not end up in configuration files
+func token(provider string) string {
+	if value := os.Getenv("MOB_PULL_REQUEST_TOKEN"); value != "" {
+		return value
+	}
+	return os.Getenv(strings.ToUpper(provider) + "_TOKEN")
+}
+
+func apiUrlOrDefault(apiUrl string, defaultApiUrl string) string {
+	if apiUrl != "" {
+		return strings.TrimSuffix(apiUrl, "/")
+	}
+	return defaultApiUrl
+}
+
+func resultFromResponse(responseBody string, urlField string) (Result, error) {
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(responseBody), &response); err != nil {
+		return Result{}, fmt.Errorf("failed to parse the response: %w", err)
+	}
+	webUrl, _ := response[urlField].(string)
+	return Result{Url: webUrl, Created: true}, nil
+}
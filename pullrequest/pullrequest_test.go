@@ -0,0 +1,179 @@
+package pullrequest_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/pullrequest"
+	"github.com/remotemobprogramming/mob/v5/test"
+)
+
+type capturedRequest struct {
+	method  string
+	path    string
+	headers http.Header
+	body    map[string]string
+}
+
+func newFakeServer(t *testing.T, status int, response string) (*httptest.Server, *capturedRequest) {
+	t.Helper()
+	captured := &capturedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured.method = r.Method
+		captured.path = r.URL.EscapedPath()
+		captured.headers = r.Header
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &captured.body)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, captured
+}
+
+var examplePullRequest = pullrequest.PullRequest{
+	Title:        "add greeting",
+	Description:  "Co-authored-by: Alice <alice@example.com>",
+	SourceBranch: "review/main",
+	TargetBranch: "main",
+}
+
+func TestParseRemoteUrl(t *testing.T) {
+	expected := pullrequest.Repository{Host: "github.com", Path: "remotemobprogramming/mob"}
+	for _, remoteUrl := range []string{
+		"https://github.com/remotemobprogramming/mob.git",
+		"https://user@github.com/remotemobprogramming/mob",
+		"git@github.com:remotemobprogramming/mob.git",
+		"ssh://git@github.com:22/remotemobprogramming/mob.git",
+	} {
+		repository, found := pullrequest.ParseRemoteUrl(remoteUrl)
+		test.Equals(t, true, found)
+		test.Equals(t, expected, repository)
+	}
+}
+
+func TestParseRemoteUrlWithSubgroups(t *testing.T) {
+	repository, found := pullrequest.ParseRemoteUrl("https://gitlab.example.com:8443/group/subgroup/project.git")
+
+	test.Equals(t, true, found)
+	test.Equals(t, pullrequest.Repository{Host: "gitlab.example.com:8443", Path: "group/subgroup/project"}, repository)
+}
+
+func TestParseRemoteUrlOfLocalRepository(t *testing.T) {
+	for _, remoteUrl := range []string{"/tmp/remote", "../remote", "file:///tmp/remote", "C:/remote"} {
+		_, found := pullrequest.ParseRemoteUrl(remoteUrl)
+		test.Equals(t, false, found)
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	test.Equals(t, pullrequest.GitHub, pullrequest.DetectProvider(pullrequest.Repository{Host: "github.com"}))
+	test.Equals(t, pullrequest.GitLab, pullrequest.DetectProvider(pullrequest.Repository{Host: "gitlab.example.com"}))
+	test.Equals(t, pullrequest.Gitea, pullrequest.DetectProvider(pullrequest.Repository{Host: "codeberg.org"}))
+	test.Equals(t, "", pullrequest.DetectProvider(pullrequest.Repository{Host: "git.example.com"}))
+}
+
+func TestGitHubProvider(t *testing.T) {
+	server, captured := newFakeServer(t, http.StatusCreated, `{"html_url": "https://github.com/owner/repo/pull/1"}`)
+	provider := pullrequest.NewGitHubProvider(pullrequest.Repository{Host: "github.com", Path: "owner/repo"}, server.URL, "secret")
+
+	result, err := provider.Open(examplePullRequest)
+
+	test.Equals(t, nil, err)
+	test.Equals(t, pullrequest.Result{Url: "https://github.com/owner/repo/pull/1", Created: true}, result)
+	test.Equals(t, "POST", captured.method)
+	test.Equals(t, "/repos/owner/repo/pulls", captured.path)
+	test.Equals(t, "Bearer secret", captured.headers.Get("Authorization"))
+	test.Equals(t, map[string]string{"title": "add greeting", "body": "Co-authored-by: Alice <alice@example.com>", "head": "review/main", "base": "main"}, captured.body)
+}
+
+func TestGitLabProvider(t *testing.T) {
+	server, captured := newFakeServer(t, http.StatusCreated, `{"web_url": "https://gitlab.com/group/project/-/merge_requests/1"}`)
+	provider := pullrequest.NewGitLabProvider(pullrequest.Repository{Host: "gitlab.com", Path: "group/project"}, server.URL, "secret")
+
+	result, err := provider.Open(examplePullRequest)
+
+	test.Equals(t, nil, err)
+	test.Equals(t, pullrequest.Result{Url: "https://gitlab.com/group/project/-/merge_requests/1", Created: true}, result)
+	test.Equals(t, "/projects/group%2Fproject/merge_requests", captured.path)
+	test.Equals(t, "secret", captured.headers.Get("PRIVATE-TOKEN"))
+	test.Equals(t, map[string]string{"title": "add greeting", "description": "Co-authored-by: Alice <alice@example.com>", "source_branch": "review/main", "target_branch": "main"}, captured.body)
+}
+
+func TestGiteaProvider(t *testing.T) {
+	server, captured := newFakeServer(t, http.StatusCreated, `{"html_url": "https://codeberg.org/owner/repo/pulls/1"}`)
+	provider := pullrequest.NewGiteaProvider(pullrequest.Repository{Host: "codeberg.org", Path: "owner/repo"}, server.URL, "secret")
+
+	result, err := provider.Open(examplePullRequest)
+
+	test.Equals(t, nil, err)
+	test.Equals(t, pullrequest.Result{Url: "https://codeberg.org/owner/repo/pulls/1", Created: true}, result)
+	test.Equals(t, "/repos/owner/repo/pulls", captured.path)
+	test.Equals(t, "token secret", captured.headers.Get("Authorization"))
+	test.Equals(t, map[string]string{"title": "add greeting", "body": "Co-authored-by: Alice <alice@example.com>", "head": "review/main", "base": "main"}, captured.body)
+}
+
+func TestApiProviderIsInactiveWithoutToken(t *testing.T) {
+	test.Equals(t, false, pullrequest.NewGitHubProvider(pullrequest.Repository{Host: "github.com", Path: "owner/repo"}, "", "").IsActive())
+}
+
+func TestUrlProvider(t *testing.T) {
+	provider := pullrequest.NewUrlProvider(pullrequest.Gitea, pullrequest.Repository{Host: "codeberg.org", Path: "owner/repo"})
+
+	result, err := provider.Open(examplePullRequest)
+
+	test.Equals(t, nil, err)
+	test.Equals(t, pullrequest.Result{Url: "https://codeberg.org/owner/repo/compare/main...review/main"}, result)
+}
+
+func TestOpenWithoutTokenReturnsUrl(t *testing.T) {
+	t.Setenv("MOB_PULL_REQUEST_TOKEN", "")
+	t.Setenv("GITLAB_TOKEN", "")
+
+	result, err := pullrequest.Open(config.GetDefaultConfiguration(), "git@gitlab.com:group/project.git", examplePullRequest)
+
+	test.Equals(t, nil, err)
+	test.Equals(t, false, result.Created)
+	test.Equals(t, "https://gitlab.com/group/project/-/merge_requests/new?merge_request%5Bdescription%5D=Co-authored-by%3A+Alice+%3Calice%40example.com%3E&merge_request%5Bsource_branch%5D=review%2Fmain&merge_request%5Btarget_branch%5D=main&merge_request%5Btitle%5D=add+greeting", result.Url)
+}
+
+func TestOpenUsesConfiguredProviderAndApiUrl(t *testing.T) {
+	server, captured := newFakeServer(t, http.StatusCreated, `{"html_url": "https://git.example.com/owner/repo/pulls/1"}`)
+	t.Setenv("MOB_PULL_REQUEST_TOKEN", "")
+	t.Setenv("GITEA_TOKEN", "secret")
+	configuration := config.GetDefaultConfiguration()
+	configuration.PullRequestProvider = pullrequest.Gitea
+	configuration.PullRequestApiUrl = server.URL + "/"
+
+	result, err := pullrequest.Open(configuration, "https://git.example.com/owner/repo.git", examplePullRequest)
+
+	test.Equals(t, nil, err)
+	test.Equals(t, true, result.Created)
+	test.Equals(t, "/repos/owner/repo/pulls", captured.path)
+	test.Equals(t, "token secret", captured.headers.Get("Authorization"))
+}
+
+func TestOpenFallsBackToUrlWhenApiFails(t *testing.T) {
+	server, _ := newFakeServer(t, http.StatusUnprocessableEntity, `{"message": "Validation Failed"}`)
+	t.Setenv("MOB_PULL_REQUEST_TOKEN", "secret")
+	configuration := config.GetDefaultConfiguration()
+	configuration.PullRequestApiUrl = server.URL
+
+	result, err := pullrequest.Open(configuration, "https://github.com/owner/repo.git", examplePullRequest)
+
+	test.Equals(t, false, err == nil)
+	test.Equals(t, false, result.Created)
+	test.Equals(t, "https://github.com/owner/repo/compare/main...review/main?body=Co-authored-by%3A+Alice+%3Calice%40example.com%3E&expand=1&title=add+greeting", result.Url)
+}
+
+func TestOpenWithUnknownProviderReturnsCompareUrl(t *testing.T) {
+	result, err := pullrequest.Open(config.GetDefaultConfiguration(), "git@git.example.com:owner/repo.git", examplePullRequest)
+
+	test.Equals(t, nil, err)
+	test.Equals(t, false, result.Created)
+	test.Equals(t, "https://git.example.com/owner/repo/compare/main...review/main", result.Url)
+}
@@ -0,0 +1,43 @@
+package pullrequest
+
+import (
+	"net/url"
+)
+
+// UrlProvider is the fallback Provider implementation that doesn't create the pull request, but returns the url of
+// the page to create it in the browser.
+type UrlProvider struct {
+	provider   string
+	repository Repository
+}
+
+func NewUrlProvider(provider string, repository Repository) UrlProvider {
+	return UrlProvider{
+		provider:   provider,
+		repository: repository,
+	}
+}
+
+func (p UrlProvider) IsActive() bool {
+	return true
+}
+
+func (p UrlProvider) Open(pullRequest PullRequest) (Result, error) {
+	switch p.provider {
+	case GitLab:
+		query := url.Values{}
+		query.Set("merge_request[source_branch]", pullRequest.SourceBranch)
+		query.Set("merge_request[target_branch]", pullRequest.TargetBranch)
+		query.Set("merge_request[title]", pullRequest.Title)
+		query.Set("merge_request[description]", pullRequest.Description)
+		return Result{Url: p.repository.webUrl() + "/-/merge_requests/new?" + query.Encode()}, nil
+	case GitHub:
+		query := url.Values{}
+		query.Set("expand", "1")
+		query.Set("title", pullRequest.Title)
+		query.Set("body", pullRequest.Description)
+		return Result{Url: p.repository.webUrl() + "/compare/" + pullRequest.TargetBranch + "..." + pullRequest.SourceBranch + "?" + query.Encode()}, nil
+	default:
+		return Result{Url: p.repository.webUrl() + "/compare/" + pullRequest.TargetBranch + "..." + pullRequest.SourceBranch}, nil
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/remotemobprogramming/mob/v5/coauthors"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	mobgit "github.com/remotemobprogramming/mob/v5/git"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// remembers for 'mob done --continue' that the rebased wip branch ends with the wip commit 'mob done' made
+const doneRebaseWipCommitFile = "mob-done-rebase-wip-commit"
+
+// rebases the wip branch onto the base branch and fast-forwards the base branch, so that no merge commit is created
+func doneRebase(configuration config.Configuration, baseBranch Branch, wipBranch Branch, sessionCoauthors []coauthors.Author, madeWipCommit bool) {
+	say.Info("rebasing '" + wipBranch.Name + "' onto '" + baseBranch.remote(configuration).Name + "'")
+	if err := gitIgnoreFailure(rebaseArgs(configuration, baseBranch)...); err != nil {
+		if madeWipCommit {
+			if err := os.WriteFile(gitDir()+"/"+doneRebaseWipCommitFile, []byte{}, 0644); err != nil {
+				say.Warning("could not remember the wip commit of '" + configuration.Mob("done") + "': " + err.Error())
+			}
+		}
+		sayDoneRebaseConflicts(configuration, wipBranch)
+		return
+	}
+	finishDoneRebase(configuration, baseBranch, wipBranch, sessionCoauthors, madeWipCommit)
+}
+
+// whether the 'mob done' that stopped because of conflicts made the last wip commit, forgets it
+func forgetDoneRebaseWipCommit() bool {
+	err := os.Remove(gitDir() + "/" + doneRebaseWipCommitFile)
+	return err == nil
+}
+
+// rebased commits are signed again, as rebasing drops their signatures
+func rebaseArgs(configuration config.Configuration, baseBranch Branch) []string {
+	args := []string{"rebase", baseBranch.remote(configuration).Name}
+	if configuration.SignCommits {
+		args = append(args, mobgit.SignOption(configuration))
+	}
+	return args
+}
+
+func continueDoneRebase(configuration config.Configuration) {
+	wipBranch := rebaseHeadBranch()
+	if len(getConflictedFiles()) > 0 {
+		sayDoneRebaseConflicts(configuration, wipBranch)
+		return
+	}
+
+	say.Info("continuing to rebase '" + wipBranch.Name + "'")
+	// keep the commit messages without opening an editor
+	originalGitEditor, originalGitSequenceEditor := getEnvGitEditor()
+	setEnvGitEditor("true", originalGitSequenceEditor)
+	err := gitIgnoreFailure("rebase", "--continue")
+	setEnvGitEditor(originalGitEditor, originalGitSequenceEditor)
+	if err != nil {
+		sayDoneRebaseConflicts(configuration, wipBranch)
+		return
+	}
+
+	baseBranch, _ := determineBranches(wipBranch, gitBranches(), configuration)
+	finishDoneRebase(configuration, baseBranch, wipBranch, collectSessionCoauthors(configuration, baseBranch, wipBranch), forgetDoneRebaseWipCommit())
+}
+
+func finishDoneRebase(configuration config.Configuration, baseBranch Branch, wipBranch Branch, sessionCoauthors []coauthors.Author, madeWipCommit bool) {
+	git("checkout", baseBranch.Name)
+	git("merge", baseBranch.remote(configuration).Name, "--ff-only")
+	git("merge", "--ff-only", wipBranch.Name)
+	commitMessage, hasCommitMessageTemplate := doneCommitMessage(configuration, baseBranch, wipBranch, sessionCoauthors)
+	git("branch", "-D", wipBranch.Name)
+
+	if madeWipCommit && lastCommitIsWipCommit(configuration) { // give the user the chance to name their final commit
+		git("reset", "--soft", "HEAD^")
+	}
+
+	if wipBranch.hasRemoteBranch(configuration) {
+		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", wipBranch.Name, sharedBaseBranchRefToDelete(wipBranch))
+	}
+	forgetBaseBranch(wipBranch)
+	clearParticipants()
+
+	cachedChanges := getCachedChanges()
+	if len(cachedChanges) > 0 {
+		say.InfoIndented(cachedChanges)
+	}
+
+	if hasStagedChanges() {
+		if hasCommitMessageTemplate {
+			prefillCommitMessage(commitMessage)
+			appendFinalCommitTrailers(configuration, "SQUASH_MSG", nil)
+		} else {
+			appendFinalCommitTrailers(configuration, "SQUASH_MSG", sessionCoauthors)
+		}
+		say.Next("To finish, use", finalCommitCommand(configuration))
+	} else {
+		finishWithLastCommit(configuration, baseBranch, sessionCoauthors, hasCommitMessageTemplate)
+		say.Next("To publish the rebased commits, use", "git push")
+	}
+}
+
+func sayDoneRebaseConflicts(configuration config.Configuration, wipBranch Branch) {
+	say.Warning("Rebasing " + wipBranch.Name + " stopped because of conflicts in:")
+	sayConflictedFiles()
+	say.Fix("To continue, solve the conflicts, stage them with 'git add' and use", configuration.Mob("done --continue"))
+	say.Fix("To abort and return to the wip branch, use", configuration.Mob("done --abort"))
+}
+
+func isDoneRebaseInProgress(configuration config.Configuration) bool {
+	if !isRebaseInProgress() {
+		return false
+	}
+	return rebaseHeadBranch().IsWipBranch(configuration)
+}
+
+func isRebaseInProgress() bool {
+	return rebaseStateDir() != ""
+}
+
+func rebaseStateDir() string {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		path := gitDir() + "/" + dir
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// the branch being rebased, as the current branch is a detached HEAD during a rebase
+func rebaseHeadBranch() Branch {
+	headName, err := os.ReadFile(rebaseStateDir() + "/head-name")
+	if err != nil {
+		say.Debug("could not read head-name of rebase: " + err.Error())
+		return newBranch("")
+	}
+	return newBranch(strings.TrimPrefix(strings.TrimSpace(string(headName)), "refs/heads/"))
+}
+
+func getConflictedFiles() []string {
+	output := silentgit("diff", "--name-only", "--diff-filter=U")
+	if output == "" {
+		return []string{}
+	}
+	return strings.Split(output, "\n")
+}
+
+func setEnvGitEditor(gitEditor string, gitSequenceEditor string) {
+	os.Setenv("GIT_EDITOR", gitEditor)
+	os.Setenv("GIT_SEQUENCE_EDITOR", gitSequenceEditor)
+}
+
+func getEnvGitEditor() (gitEditor string, gitSequenceEditor string) {
+	gitEditor = os.Getenv("GIT_EDITOR")
+	gitSequenceEditor = os.Getenv("GIT_SEQUENCE_EDITOR")
+	return
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestDoneRebaseCreatesLinearHistory(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.DoneSquash = config.Rebase
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFileAndCommitIt(t, "manual.txt", "manual", "manual commit")
+	createFile(t, "wip.txt", "wip")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "other.txt", "other", "other commit")
+	git("push")
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	assertCommitLogContainsMessage(t, "master", "manual commit")
+	assertCommitLogNotContainsMessage(t, "master", configuration.WipCommitMessage)
+	equals(t, "", silentgit("rev-list", "--merges", "master"))
+	equals(t, "other commit", silentgit("log", "-1", "--format=%s", "HEAD^"))
+	assertGitStatus(t, GitStatus{
+		"wip.txt": "A",
+	})
+	assertOutputContains(t, output, "git commit")
+}
+
+func TestDoneRebaseWithoutUncommittedChanges(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.DoneSquash = config.Rebase
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFileAndCommitIt(t, "manual.txt", "manual", "manual commit")
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	equals(t, "manual commit", silentgit("log", "-1", "--format=%s"))
+	assertGitStatus(t, GitStatus{})
+	assertOutputContains(t, output, "git push")
+}
+
+func TestDoneRebaseConflictCanBeContinued(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.DoneSquash = config.Rebase
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFileAndCommitIt(t, "example.txt", "content", "manual commit")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "asdf", "other commit")
+	git("push")
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	done(configuration)
+
+	assertOutputContains(t, output, "Rebasing mob-session stopped because of conflicts in:")
+	assertOutputContains(t, output, "  - example.txt")
+	assertOutputContains(t, output, "mob done --continue")
+
+	doneContinue(configuration)
+	assertOutputContains(t, output, "Rebasing mob-session stopped because of conflicts in:")
+
+	createFile(t, "example.txt", "resolved")
+	git("add", "example.txt")
+	doneContinue(configuration)
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	equals(t, "", silentgit("rev-list", "--merges", "master"))
+	equals(t, "manual commit", silentgit("log", "-1", "--format=%s"))
+	equals(t, "resolved", readFile(t, tempDir+"/local/example.txt"))
+}
+
+func TestDoneRebaseConflictCanBeAborted(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.Rebase
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFileAndCommitIt(t, "example.txt", "content", "manual commit")
+
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "asdf", "other commit")
+	git("push")
+
+	setWorkingDir(tempDir + "/local")
+	done(configuration)
+	doneAbort(configuration)
+
+	assertOnBranch(t, "mob-session")
+	equals(t, "manual commit", silentgit("log", "-1", "--format=%s"))
+	assertGitStatus(t, GitStatus{})
+}
+
+func TestDoneRebaseConflictUndoesWipCommitOfDoneAfterContinue(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.Rebase
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "example.txt", "content")
+
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "asdf", "other commit")
+	git("push")
+
+	setWorkingDir(tempDir + "/local")
+	done(configuration)
+	createFile(t, "example.txt", "resolved")
+	git("add", "example.txt")
+	doneContinue(configuration)
+
+	assertOnBranch(t, "master")
+	equals(t, "other commit", silentgit("log", "-1", "--format=%s"))
+	assertGitStatus(t, GitStatus{
+		"example.txt": "M",
+	})
+	equals(t, false, forgetDoneRebaseWipCommit())
+}
+
+func TestDoneRebaseKeepsWipCommitItDidNotMake(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.Rebase
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "example.txt", "content")
+	next(configuration)
+	start(configuration)
+	baseBranch, wipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+
+	doneRebase(configuration, baseBranch, wipBranch, nil, false)
+
+	assertOnBranch(t, "master")
+	equals(t, configuration.WipCommitMessage, silentgit("log", "-1", "--format=%s"))
+	assertGitStatus(t, GitStatus{})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/input"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// a remote wip branch of the base branch
+type wipSession struct {
+	branch        Branch // e.g. origin/mob/main-green
+	qualifier     string
+	lastCommitter string
+	lastActivity  int64  // unix timestamp of the last commit
+	age           string // e.g. "5 minutes ago"
+}
+
+// asks which existing session to join, when 'mob start' runs in a terminal without a qualifier and the default wip
+// branch does not exist yet
+func shouldPickSession(configuration config.Configuration, currentWipBranch Branch) bool {
+	return configuration.WipBranchQualifier == "" &&
+		!currentWipBranch.hasRemoteBranch(configuration) &&
+		!isMobProgramming(configuration) &&
+		input.IsInteractive()
+}
+
+// returns the configuration with the qualifier of the chosen session, or of the new session
+func pickSession(configuration config.Configuration, currentBaseBranch Branch) (config.Configuration, error) {
+	sessions := getQualifiedSessions(currentBaseBranch, configuration)
+	if len(sessions) == 0 {
+		return configuration, nil
+	}
+
+	say.Info("existing sessions for base branch '" + currentBaseBranch.String() + "':")
+	for i, session := range sessions {
+		say.WithPrefix(session.qualifier+"\t"+session.lastCommitter+"\t"+session.age, "  "+strconv.Itoa(i+1)+") ")
+	}
+	if !configuration.StartJoin {
+		say.WithPrefix("start a new session", "  n) ")
+	}
+
+	answer := strings.ToLower(input.Ask("Which session do you want to join?", "1"))
+	if answer == "n" && !configuration.StartJoin {
+		configuration.WipBranchQualifier = input.Ask("Name of the new session (leave empty for none):", "")
+		return configuration, nil
+	}
+	selected, err := strconv.Atoi(answer)
+	if err != nil || selected < 1 || selected > len(sessions) {
+		say.Error("There is no session '" + answer + "'.")
+		return configuration, errors.New("no such session")
+	}
+	configuration.WipBranchQualifier = sessions[selected-1].qualifier
+	return configuration, nil
+}
+
+// the remote wip branches with a qualifier, the most recently active first
+func getQualifiedSessions(currentBaseBranch Branch, configuration config.Configuration) []wipSession {
+	prefix := currentBaseBranch.addWipPrefix(configuration).remote(configuration).Name + configuration.WipBranchQualifierSeparator
+	var sessions []wipSession
+	for _, remoteBranch := range getWipBranchesForBaseBranch(currentBaseBranch, configuration) {
+		qualifier := strings.TrimPrefix(remoteBranch, prefix)
+		if !strings.HasPrefix(remoteBranch, prefix) || qualifier == "" {
+			continue
+		}
+		session := wipSession{branch: newBranch(remoteBranch), qualifier: qualifier}
+		fields := strings.SplitN(silentgit("log", "-1", "--format=%ct%x09%cr%x09%an", remoteBranch), "\t", 3)
+		if len(fields) == 3 {
+			session.lastActivity, _ = strconv.ParseInt(fields[0], 10, 64)
+			session.age, session.lastCommitter = fields[1], fields[2]
+		}
+		sessions = append(sessions, session)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].lastActivity > sessions[j].lastActivity
+	})
+	return sessions
+}
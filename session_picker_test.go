@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestStartPicksExistingSession(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+
+	setWorkingDir(tempDir + "/alice")
+	mockInteractiveInput(t, "1\n")
+	start(configuration)
+
+	assertOutputContains(t, output, "existing sessions for base branch 'master':")
+	assertOutputContains(t, output, "1) green\tlocal\t")
+	assertOnBranch(t, "mob/master-green")
+}
+
+func TestStartPickerCreatesNewSession(t *testing.T) {
+	_, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+
+	setWorkingDir(tempDir + "/alice")
+	mockInteractiveInput(t, "n\nblue\n")
+	start(configuration)
+
+	assertOnBranch(t, "mob/master-blue")
+}
+
+func TestStartPickerCreatesUnqualifiedSession(t *testing.T) {
+	_, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+
+	setWorkingDir(tempDir + "/alice")
+	mockInteractiveInput(t, "n\n\n")
+	start(configuration)
+
+	assertOnBranch(t, "mob-session")
+}
+
+func TestStartPickerUnknownSession(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+
+	setWorkingDir(tempDir + "/alice")
+	mockInteractiveInput(t, "2\n")
+	err := start(configuration)
+
+	equals(t, "no such session", err.Error())
+	assertOutputContains(t, output, "There is no session '2'.")
+	assertOnBranch(t, "master")
+}
+
+func TestStartJoinPickerOffersNoNewSession(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+
+	setWorkingDir(tempDir + "/alice")
+	configuration.StartJoin = true
+	mockInteractiveInput(t, "n\n")
+	err := start(configuration)
+
+	assertOutputNotContains(t, output, "start a new session")
+	equals(t, "no such session", err.Error())
+	assertOnBranch(t, "master")
+}
+
+func TestStartWithoutTerminalDoesNotPickSession(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+
+	assertOutputNotContains(t, output, "existing sessions for base branch")
+	assertOnBranch(t, "mob-session")
+}
+
+func startQualifiedSession(t *testing.T, configuration config.Configuration, qualifier string) {
+	configuration.WipBranchQualifier = qualifier
+	start(configuration)
+	createFile(t, qualifier+".txt", "contentIrrelevant")
+	next(configuration)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/remotemobprogramming/mob/v5/coauthors"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// Session is a remote wip branch as shown by 'mob branch'
+type Session struct {
+	BaseBranch   string    `json:"baseBranch"` // empty if the base branch does not exist on the remote
+	Branch       string    `json:"branch"`     // e.g. origin/mob/main-green
+	Qualifier    string    `json:"qualifier"`
+	WipCommits   int       `json:"wipCommits"`
+	Participants []string  `json:"participants"`
+	LastActivity time.Time `json:"lastActivity"`
+	Ahead        int       `json:"ahead"`  // commits of the wip branch missing on the base branch
+	Behind       int       `json:"behind"` // commits of the base branch missing on the wip branch
+	Stale        bool      `json:"stale"`
+}
+
+func branch(configuration config.Configuration, parameter []string) {
+	sessions := getSessions(configuration)
+	if stringContains(parameter, "--json") {
+		output, err := json.MarshalIndent(sessions, "", "  ")
+		if err != nil {
+			say.Error("Could not write the sessions as JSON: " + err.Error())
+			return
+		}
+		say.Say(string(output))
+		return
+	}
+	if len(sessions) == 0 {
+		say.Info("there are no sessions on " + configuration.RemoteName)
+		return
+	}
+	say.Say(sessionsTable(sessions))
+}
+
+func sessionsTable(sessions []Session) string {
+	var table strings.Builder
+	writer := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	baseBranch := ""
+	for i, session := range sessions {
+		if i == 0 || session.BaseBranch != baseBranch {
+			baseBranch = session.BaseBranch
+			if i > 0 {
+				fmt.Fprintln(writer)
+			}
+			if baseBranch == "" {
+				fmt.Fprintln(writer, "without base branch")
+			} else {
+				fmt.Fprintln(writer, "base branch '"+baseBranch+"'")
+			}
+			fmt.Fprintln(writer, "  BRANCH\tQUALIFIER\tWIP COMMITS\tPARTICIPANTS\tLAST ACTIVITY\tAHEAD\tBEHIND\t")
+		}
+		stale := ""
+		if session.Stale {
+			stale = "stale"
+		}
+		fmt.Fprintf(writer, "  %s\t%s\t%d\t%s\t%s\t%d\t%d\t%s\n", session.Branch, session.Qualifier, session.WipCommits,
+			strings.Join(session.Participants, ", "), session.LastActivity.Format("2006-01-02 15:04"), session.Ahead, session.Behind, stale)
+	}
+	writer.Flush()
+	return strings.TrimRight(table.String(), "\n")
+}
+
+// all remote wip branches, sorted by base branch and the most recent activity first
+func getSessions(configuration config.Configuration) []Session {
+	remoteBranches := gitRemoteBranches()
+	remotePrefix := configuration.RemoteName + "/"
+	baseBranches := remoteBaseBranches(remoteBranches, configuration)
+	var wipBranches []string // the base branches of stacked sessions
+	for _, remoteBranch := range remoteBranches {
+		if name := strings.TrimPrefix(remoteBranch, remotePrefix); newBranch(name).IsWipBranch(configuration) {
+			wipBranches = append(wipBranches, name)
+		}
+	}
+
+	registry := readAuthorRegistry()
+	staleBefore := time.Now().Add(-configuration.SessionStaleAfterDuration())
+	sessions := []Session{}
+	for _, remoteBranch := range remoteBranches {
+		wipBranch := newBranch(strings.TrimPrefix(remoteBranch, remotePrefix))
+		if !strings.HasPrefix(remoteBranch, remotePrefix) || !wipBranch.IsWipBranch(configuration) {
+			continue
+		}
+		session := Session{Branch: remoteBranch, Participants: []string{}}
+		if isStackedSession(wipBranch.removeWipPrefix(configuration), configuration) {
+			session.BaseBranch, session.Qualifier = splitWipBranch(wipBranch, wipBranches, configuration)
+		} else {
+			session.BaseBranch, session.Qualifier = splitWipBranch(wipBranch, baseBranches, configuration)
+		}
+		if session.BaseBranch != "" {
+			revisions := newBranch(session.BaseBranch).remote(configuration).Name + ".." + remoteBranch
+			session.WipCommits = countWipCommits(configuration, revisions)
+			session.Participants = sessionParticipants(registry, revisions)
+			session.Ahead, session.Behind = aheadBehind(newBranch(session.BaseBranch).remote(configuration).Name, remoteBranch)
+		}
+		if timestamp, err := strconv.ParseInt(silentgit("log", "-1", "--format=%ct", remoteBranch), 10, 64); err == nil {
+			session.LastActivity = time.Unix(timestamp, 0)
+		}
+		session.Stale = session.LastActivity.Before(staleBefore)
+		sessions = append(sessions, session)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if sessions[i].BaseBranch != sessions[j].BaseBranch {
+			return sessions[i].BaseBranch < sessions[j].BaseBranch
+		}
+		return sessions[i].LastActivity.After(sessions[j].LastActivity)
+	})
+	return sessions
+}
+
+// the names of the remote branches that are not wip branches, without the remote
+func remoteBaseBranches(remoteBranches []string, configuration config.Configuration) []string {
+	remotePrefix := configuration.RemoteName + "/"
+	var baseBranches []string
+	for _, remoteBranch := range remoteBranches {
+		name := strings.TrimPrefix(remoteBranch, remotePrefix)
+		if strings.HasPrefix(remoteBranch, remotePrefix) && name != "HEAD" && !newBranch(name).IsWipBranch(configuration) {
+			baseBranches = append(baseBranches, name)
+		}
+	}
+	return baseBranches
+}
+
+// the base branch recorded by 'mob start' for the wip branch, else the longest base branch the wip branch belongs to,
+// e.g. 'mob/feature-x-green' belongs to 'feature-x' rather than 'feature' if both exist
+func splitWipBranch(wipBranch Branch, baseBranches []string, configuration config.Configuration) (baseBranch string, qualifier string) {
+	if configuration.LegacyMobSession && wipBranch.Is(legacyWipBranch) {
+		// DEPRECATED
+		return legacyBaseBranch, ""
+	}
+	if remembered, found := rememberedBaseBranch(wipBranch); found {
+		if rememberedQualifier := wipBranchQualifierOf(wipBranch, remembered, configuration); rememberedQualifier != nil {
+			return remembered.Name, *rememberedQualifier
+		}
+	}
+	name := wipBranch.removeWipPrefix(configuration).Name
+	for _, candidate := range baseBranches {
+		if len(candidate) <= len(baseBranch) {
+			continue
+		}
+		if name == candidate {
+			baseBranch, qualifier = candidate, ""
+		} else if strings.HasPrefix(name, candidate+configuration.WipBranchQualifierSeparator) {
+			baseBranch, qualifier = candidate, strings.TrimPrefix(name, candidate+configuration.WipBranchQualifierSeparator)
+		}
+	}
+	return baseBranch, qualifier
+}
+
+// the merges of 'mob sync' are wip commits, but no rotations
+func countWipCommits(configuration config.Configuration, revisions string) int {
+	subjects, err := silentgitignorefailure("log", "--format=%s", "--invert-grep", "--grep=^"+syncTrailer, revisions)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, subject := range strings.Split(subjects, "\n") {
+		if subject != "" && configuration.IsWipCommitMessage(subject) {
+			count++
+		}
+	}
+	return count
+}
+
+// the names of the authors, resolved with the registry, in the order of their first commit
+func sessionParticipants(registry coauthors.Registry, revisions string) []string {
+	authors, err := silentgitignorefailure("log", "--reverse", "--format=%aN <%aE>", revisions)
+	participants := []string{}
+	if err != nil {
+		return participants
+	}
+	for _, author := range strings.Split(authors, "\n") {
+		if author == "" {
+			continue
+		}
+		if name := coauthors.Name(registry.Canonical(author)); !stringContains(participants, name) {
+			participants = append(participants, name)
+		}
+	}
+	return participants
+}
+
+func aheadBehind(baseBranch string, wipBranch string) (ahead int, behind int) {
+	counts := strings.Fields(silentgit("rev-list", "--left-right", "--count", baseBranch+"..."+wipBranch))
+	if len(counts) == 2 {
+		behind, _ = strconv.Atoi(counts[0])
+		ahead, _ = strconv.Atoi(counts[1])
+	}
+	return ahead, behind
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestBranchShowsSessionsOfAllBaseBranches(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	startQualifiedSession(t, configuration, "green")
+	setWorkingDir(tempDir + "/alice")
+	git("checkout", "-b", "feature-x")
+	git("push", "origin", "feature-x", "--set-upstream")
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	next(configuration)
+
+	branch(configuration, []string{})
+
+	assertOutputContains(t, output, "base branch 'feature-x'\n  BRANCH")
+	assertOutputContains(t, output, "  origin/mob/feature-x ")
+	assertOutputContains(t, output, "base branch 'master'\n  BRANCH")
+	assertOutputContains(t, output, "  origin/mob/master-green ")
+	assertOutputNotContains(t, output, "stale")
+}
+
+func TestGetSessions(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	next(configuration)
+	setWorkingDir(tempDir + "/bob")
+	createFileAndCommitIt(t, "file3.txt", "contentIrrelevant", "new base commit")
+	git("push", "origin", "master")
+	git("fetch")
+
+	sessions := getSessions(configuration)
+
+	equals(t, 1, len(sessions))
+	equals(t, "master", sessions[0].BaseBranch)
+	equals(t, "origin/mob-session", sessions[0].Branch)
+	equals(t, "", sessions[0].Qualifier)
+	equals(t, 2, sessions[0].WipCommits)
+	equals(t, []string{"local", "alice"}, sessions[0].Participants)
+	equals(t, 2, sessions[0].Ahead)
+	equals(t, 1, sessions[0].Behind)
+	equals(t, false, sessions[0].Stale)
+}
+
+func TestBranchFlagsStaleSessions(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	configuration.SessionStaleAfter = "0m"
+
+	branch(configuration, []string{})
+
+	assertOutputContains(t, output, "stale")
+	equals(t, true, getSessions(configuration)[0].Stale)
+}
+
+func TestBranchAsJson(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+
+	branch(configuration, []string{"--json"})
+
+	assertOutputContains(t, output, "\"branch\": \"origin/mob/master-green\"")
+	assertOutputContains(t, output, "\"qualifier\": \"green\"")
+	assertOutputContains(t, output, "\"wipCommits\": 1")
+	assertOutputContains(t, output, "\"stale\": false")
+}
+
+func TestBranchWithoutSessions(t *testing.T) {
+	output, configuration := setup(t)
+
+	branch(configuration, []string{})
+
+	assertOutputContains(t, output, "there are no sessions on origin")
+}
+
+func TestGetSessionsUsesSharedBaseBranch(t *testing.T) {
+	_, configuration := setup(t)
+	git("checkout", "-b", "feature")
+	git("push", "origin", "feature", "--set-upstream")
+	git("checkout", "-b", "feature-x")
+	git("push", "origin", "feature-x", "--set-upstream")
+	git("checkout", "feature")
+	qualifiedConfiguration := configuration
+	qualifiedConfiguration.WipBranchQualifier = "x"
+	start(qualifiedConfiguration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(qualifiedConfiguration)
+
+	setWorkingDir(tempDir + "/bob")
+	fetchBaseBranchesWithRemote(configuration)
+	git("fetch", "origin")
+	sessions := getSessions(configuration)
+
+	equals(t, 1, len(sessions))
+	equals(t, "feature", sessions[0].BaseBranch)
+	equals(t, "x", sessions[0].Qualifier)
+	equals(t, 1, sessions[0].WipCommits)
+}
+
+func TestSplitWipBranch(t *testing.T) {
+	_, configuration := setup(t)
+	baseBranches := []string{"main", "feature", "feature-x"}
+
+	assertSplitWipBranch(t, "feature-x", "green", "mob/feature-x-green", baseBranches, configuration)
+	assertSplitWipBranch(t, "feature-x", "", "mob/feature-x", baseBranches, configuration)
+	assertSplitWipBranch(t, "feature", "y", "mob/feature-y", baseBranches, configuration)
+	assertSplitWipBranch(t, "main", "", "mob/main", baseBranches, configuration)
+	assertSplitWipBranch(t, "", "", "mob/deleted", baseBranches, configuration)
+	assertSplitWipBranch(t, "master", "", "mob-session", baseBranches, configuration)
+}
+
+func assertSplitWipBranch(t *testing.T, expectedBaseBranch string, expectedQualifier string, wipBranch string, baseBranches []string, configuration config.Configuration) {
+	baseBranch, qualifier := splitWipBranch(newBranch(wipBranch), baseBranches, configuration)
+	equals(t, expectedBaseBranch, baseBranch)
+	equals(t, expectedQualifier, qualifier)
+}
@@ -1,235 +1,216 @@
 package main
 
 import (
-	config "github.com/remotemobprogramming/mob/v5/configuration"
-	"github.com/remotemobprogramming/mob/v5/say"
-	"io"
+	"errors"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/remotemobprogramming/mob/v5/coauthors"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
 )
 
-type Replacer func(string) string
+type commit struct {
+	Hash        string
+	Tree        string
+	Parents     []string
+	AuthorName  string
+	AuthorEmail string
+	AuthorDate  string
+	Message     string
+}
 
-func squashWip(configuration config.Configuration) {
-	if hasUncommittedChanges() {
-		makeWipCommit(configuration)
-	}
+// returns an error without touching the wip branch, if a rewritten commit could not be signed
+func squashWip(configuration config.Configuration) error {
+	madeWipCommit := hasUncommittedChanges() && makeWipCommit(configuration)
 	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 	mergeBase := silentgit("merge-base", currentWipBranch.String(), currentBaseBranch.remote(configuration).String())
 
-	originalGitEditor, originalGitSequenceEditor := getEnvGitEditor()
-	setEnvGitEditor(
-		mobExecutable()+" squash-wip --git-editor",
-		mobExecutable()+" squash-wip --git-sequence-editor",
-	)
 	say.Info("rewriting history of '" + currentWipBranch.String() + "': squashing wip commits while keeping manual commits.")
-	git("rebase", "--interactive", "--keep-empty", mergeBase)
-	setEnvGitEditor(originalGitEditor, originalGitSequenceEditor)
+	newHead, err := squashWipCommits(mergeBase, configuration)
+	if err != nil {
+		if madeWipCommit {
+			git("reset", "--quiet", "HEAD^")
+		}
+		return err
+	}
+	if newHead != silentgit("rev-parse", "HEAD") {
+		// the tree of the new head equals the tree of the old head, so index and working tree stay untouched
+		git("reset", "--soft", newHead)
+	}
 	say.Info("resulting history is:")
 	sayLastCommitsWithMessage(currentBaseBranch.remote(configuration).String(), currentWipBranch.String())
-	if lastCommitIsWipCommit(configuration) { // last commit is wip commit
+	if lastCommitIsWipCommit(configuration) && len(readCommit("HEAD").Parents) == 1 { // last commit is wip commit, but no merge
 		say.Info("undoing the final wip commit and staging its changes:")
 		git("reset", "--soft", "HEAD^")
 	}
 
 	git("push", "--force", gitHooksOption(configuration))
-}
-
-func lastCommitIsWipCommit(configuration config.Configuration) bool {
-	return strings.HasPrefix(lastCommitMessage(), configuration.WipCommitMessage)
-}
-
-func lastCommitMessage() string {
-	return silentgit("log", "-1", "--pretty=format:%B")
-}
+	return nil
+}
+
+// builds the new history on top of the fork point of the wip branch from the trees of the existing commits and returns
+// the new head: wip commits are squashed into the following manual commit, the final wip commits are squashed into one
+// wip commit and start commits are dropped. Merges, e.g. of the base branch by 'mob sync', are kept, so that every
+// commit is rebuilt on top of a commit with the same tree as its original parent.
+func squashWipCommits(mergeBase string, configuration config.Configuration) (string, error) {
+	newHead := mergeBase
+	var firstWipCommit, lastWipCommit *commit
+	for i, hash := range commitsSince(mergeBase) {
+		current := readCommit(hash)
+		if i == 0 && len(current.Parents) > 0 {
+			// differs from mergeBase, if the base branch was merged into the wip branch
+			newHead = current.Parents[0]
+		}
+		isMerge := len(current.Parents) > 1
+		if !isMerge && strings.HasPrefix(current.Message, configuration.StartCommitMessage) {
+			continue
+		}
+		if !isMerge && configuration.IsWipCommitMessage(current.Message) {
+			if firstWipCommit == nil {
+				firstWipCommit = &current
+			}
+			lastWipCommit = &current
+			continue
+		}
+		var err error
+		if isMerge && firstWipCommit != nil {
+			if newHead, err = squashedWipCommit(*firstWipCommit, *lastWipCommit, newHead, configuration); err != nil {
+				return "", err
+			}
+			firstWipCommit, lastWipCommit = nil, nil
+		}
 
-func sayLastCommitsWithMessage(currentBaseBranch string, currentWipBranch string) {
-	commitsBaseWipBranch := currentBaseBranch + ".." + currentWipBranch
-	log := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=oneline", "--abbrev-commit")
-	lines := strings.Split(log, "\n")
-	if len(lines) > 10 {
-		say.Info("wip branch '" + currentWipBranch + "' contains " + strconv.Itoa(len(lines)) + " commits. The last 10 were:")
-		lines = lines[:10]
+		if firstWipCommit == nil && len(current.Parents) > 0 && current.Parents[0] == newHead {
+			newHead = current.Hash // unchanged, keep the commit as it is
+		} else {
+			if firstWipCommit != nil {
+				current.Message = withCoauthorsOfWipCommits(current, *firstWipCommit, *lastWipCommit)
+			}
+			if newHead, err = commitTree(current, current.Tree, append([]string{newHead}, current.Parents[1:]...), configuration.SignCommits || isSignedCommit(current.Hash)); err != nil {
+				return "", err
+			}
+		}
+		firstWipCommit, lastWipCommit = nil, nil
 	}
-	output := strings.Join(lines, "\n")
-	say.Say(output)
-}
-
-func setEnvGitEditor(gitEditor string, gitSequenceEditor string) {
-	os.Setenv("GIT_EDITOR", gitEditor)
-	os.Setenv("GIT_SEQUENCE_EDITOR", gitSequenceEditor)
-}
-
-func getEnvGitEditor() (gitEditor string, gitSequenceEditor string) {
-	gitEditor = os.Getenv("GIT_EDITOR")
-	gitSequenceEditor = os.Getenv("GIT_SEQUENCE_EDITOR")
-	return
-}
-
-func mobExecutable() string {
-	if isTestEnvironment() {
-		wd, _ := os.Getwd()
-		// Convert Windows path separators to /, so they work in git bash. No-op for non-Windows paths.
-		wd = filepath.ToSlash(wd)
-		return "cd " + wd + " && go run $(ls -1 ./*.go | grep -v _test.go)"
-	} else {
-		return "mob"
+	if firstWipCommit != nil {
+		return squashedWipCommit(*firstWipCommit, *lastWipCommit, newHead, configuration)
 	}
+	return newHead, nil
 }
 
-func isTestEnvironment() bool {
-	cliName := currentCliName(os.Args[0])
-	return strings.HasSuffix(cliName, ".test") ||
-		strings.HasSuffix(cliName, "_test") ||
-		os.Args[1] == "-test.v"
-}
-
-// used for non-interactive fixing of commit messages of squashed commits
-func squashWipGitEditor(fileName string, configuration config.Configuration) {
-	replaceFileContents(fileName, func(input string) string {
-		return commentWipCommits(input, configuration)
-	})
-}
-
-// used for non-interactive rebase to squash post-wip-commits
-func squashWipGitSequenceEditor(fileName string, configuration config.Configuration) {
-	replaceFileContents(fileName, func(input string) string {
-		result := markPostWipCommitsForSquashing(input, configuration)
-		return markStartCommitForDropping(result, configuration)
-	})
-}
-
-func replaceFileContents(fileName string, replacer Replacer) {
-	file, _ := os.OpenFile(fileName, os.O_RDWR, 0666)
-	input, err := io.ReadAll(file)
-	if err != nil {
-		panic(err)
+// one wip commit on top of newHead with the changes of the wip commits from first to last
+func squashedWipCommit(first commit, last commit, newHead string, configuration config.Configuration) (string, error) {
+	if first.Hash == last.Hash && len(first.Parents) > 0 && first.Parents[0] == newHead {
+		return first.Hash, nil // unchanged, keep the commit as it is
 	}
-
-	result := replacer(string(input))
-
-	file.Seek(0, io.SeekStart)
-	file.Truncate(0)
-	file.WriteString(result)
-	file.Close()
+	return commitTree(first, last.Tree, []string{newHead}, configuration.SignCommits)
 }
 
-func commentWipCommits(input string, configuration config.Configuration) string {
-	var result []string
-	ignoreBlock := false
-	lines := strings.Split(input, "\n")
-	for idx, line := range lines {
-		if configuration.IsWipCommitMessage(line) {
-			ignoreBlock = true
-		} else if line == "" && isNextLineComment(lines, idx) {
-			ignoreBlock = false
-		}
-
-		if ignoreBlock {
-			result = append(result, "# "+line)
-		} else {
-			result = append(result, line)
+// the message of the manual commit with the authors of the wip commits from first to last, which are squashed into it,
+// as co-authors
+func withCoauthorsOfWipCommits(manual commit, first commit, last commit) string {
+	revisions := []string{last.Hash}
+	if len(first.Parents) > 0 {
+		revisions = append(revisions, "^"+first.Parents[0])
+	}
+	var trailers []string
+	for _, trailer := range coauthors.TrailerLines(coauthors.Collect(gitClient, readAuthorRegistry(), manual.author(), revisions...)) {
+		if !strings.Contains(manual.Message, trailer) {
+			trailers = append(trailers, trailer)
 		}
 	}
-	return strings.Join(result, "\n")
+	return appendTrailers(manual.Message, trailers)
 }
 
-func isNextLineComment(lines []string, currentLineIndex int) bool {
-	return len(lines) > currentLineIndex+1 && strings.HasPrefix(lines[currentLineIndex+1], "#")
-}
-
-func markPostWipCommitsForSquashing(input string, configuration config.Configuration) string {
-	var result []string
-
-	inputLines := strings.Split(input, "\n")
-	for index := range inputLines {
-		markedLine := markLine(inputLines, index, configuration)
-		result = append(result, markedLine)
+// commits on the first parent line since mergeBase, oldest first
+func commitsSince(mergeBase string) []string {
+	output := silentgit("rev-list", "--reverse", "--first-parent", mergeBase+"..HEAD")
+	if output == "" {
+		return []string{}
 	}
-
-	return strings.Join(result, "\n")
+	return strings.Split(output, "\n")
 }
 
-func markStartCommitForDropping(input string, configuration config.Configuration) string {
-	inputLines := strings.Split(input, "\n")
-	result := inputLines
-
-	firstLine := inputLines[0]
-	if isStartCISkipCommitLine(firstLine, configuration) {
-		markedLine := markDrop(firstLine)
-		result[0] = markedLine
-	}
-
-	return strings.Join(result, "\n")
+func (c commit) author() coauthors.Author {
+	return c.AuthorName + " <" + c.AuthorEmail + ">"
 }
 
-func markLine(inputLines []string, i int, configuration config.Configuration) string {
-	var resultLine = inputLines[i]
-	previousLine := previousLine(inputLines, i)
-	if isWipCommitLine(previousLine, configuration) {
-		forthComingLines := inputLines[i:]
-
-		if hasOnlyWipCommits(forthComingLines, configuration) {
-			resultLine = markFixup(inputLines[i])
-		} else {
-			resultLine = markSquash(inputLines[i])
-		}
+func readCommit(hash string) commit {
+	fields := strings.SplitN(silentgit("show", "--no-patch", "--date=raw", "--format=%T%x00%P%x00%an%x00%ae%x00%ad%x00%B", hash), "\x00", 6)
+	return commit{
+		Hash:        hash,
+		Tree:        fields[0],
+		Parents:     strings.Fields(fields[1]),
+		AuthorName:  fields[2],
+		AuthorEmail: fields[3],
+		AuthorDate:  fields[4],
+		Message:     fields[5],
 	}
-	return resultLine
 }
 
-func previousLine(inputLines []string, currentIndex int) string {
-	var previousLine = ""
-	if currentIndex > 0 {
-		previousLine = inputLines[currentIndex-1]
+// creates a commit with the given tree and parents, keeping author and message of the original commit.
+// A rewritten commit loses its signature, so signed commits are signed again with the key of the current user. If that
+// fails, an error is returned rather than an unsigned commit, which repositories requiring signed commits would reject.
+func commitTree(original commit, tree string, parents []string, sign bool) (string, error) {
+	args := []string{"commit-tree", tree, "-m", original.Message}
+	for _, parent := range parents {
+		args = append(args, "-p", parent)
 	}
-	return previousLine
-}
-
-func hasOnlyWipCommits(forthComingLines []string, configuration config.Configuration) bool {
-	var onlyWipCommits = true
-	for _, forthComingLine := range forthComingLines {
-		if isPick(forthComingLine) && isManualCommit(forthComingLine, configuration) {
-			onlyWipCommits = false
+	originalAuthor := getEnvGitAuthor()
+	setEnvGitAuthor(original.AuthorName, original.AuthorEmail, original.AuthorDate)
+	defer setEnvGitAuthor(originalAuthor[0], originalAuthor[1], originalAuthor[2])
+	if sign {
+		signedCommit, err := silentgitignorefailure(append(args, "-S")...)
+		if err != nil {
+			return "", errors.New("could not sign the rewritten commit of '" + strings.SplitN(original.Message, "\n", 2)[0] + "': " + err.Error())
 		}
+		return signedCommit, nil
 	}
-	return onlyWipCommits
+	return silentgit(args...), nil
 }
 
-func markSquash(line string) string {
-	return strings.Replace(line, "pick ", "squash ", 1)
+func isSignedCommit(hash string) bool {
+	header, _, _ := strings.Cut(silentgit("cat-file", "commit", hash), "\n\n")
+	return strings.Contains(header, "\ngpgsig ") || strings.Contains(header, "\ngpgsig-sha256 ")
 }
 
-func markFixup(line string) string {
-	return strings.Replace(line, "pick ", "fixup ", 1)
-}
+var gitAuthorEnvironmentVariables = [3]string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_AUTHOR_DATE"}
 
-func markDrop(line string) string {
-	return strings.Replace(line, "pick ", "drop ", 1)
-}
-
-func isWipCommitLine(line string, configuration config.Configuration) bool {
-	return isPick(line) && isWipCommit(line, configuration)
-}
-
-func isStartCISkipCommitLine(line string, configuration config.Configuration) bool {
-	return isPick(line) && isStartCISkipCommit(line, configuration)
+func getEnvGitAuthor() (author [3]string) {
+	for i, key := range gitAuthorEnvironmentVariables {
+		author[i] = os.Getenv(key)
+	}
+	return
 }
 
-func isManualCommit(line string, configuration config.Configuration) bool {
-	return !isWipCommit(line, configuration) && !isStartCISkipCommit(line, configuration)
+func setEnvGitAuthor(name string, email string, date string) {
+	for i, value := range []string{name, email, date} {
+		if value == "" {
+			os.Unsetenv(gitAuthorEnvironmentVariables[i])
+		} else {
+			os.Setenv(gitAuthorEnvironmentVariables[i], value)
+		}
+	}
 }
 
-func isWipCommit(line string, configuration config.Configuration) bool {
-	return strings.Contains(line, configuration.WipCommitMessage)
+func lastCommitIsWipCommit(configuration config.Configuration) bool {
+	return strings.HasPrefix(lastCommitMessage(), configuration.WipCommitMessage)
 }
 
-func isStartCISkipCommit(line string, configuration config.Configuration) bool {
-	return strings.Contains(line, configuration.StartCommitMessage)
+func lastCommitMessage() string {
+	return silentgit("log", "-1", "--pretty=format:%B")
 }
 
-func isPick(line string) bool {
-	return strings.HasPrefix(line, "pick ")
+func sayLastCommitsWithMessage(currentBaseBranch string, currentWipBranch string) {
+	commitsBaseWipBranch := currentBaseBranch + ".." + currentWipBranch
+	log := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=oneline", "--abbrev-commit")
+	lines := strings.Split(log, "\n")
+	if len(lines) > 10 {
+		say.Info("wip branch '" + currentWipBranch + "' contains " + strconv.Itoa(len(lines)) + " commits. The last 10 were:")
+		lines = lines[:10]
+	}
+	output := strings.Join(lines, "\n")
+	say.Say(output)
 }
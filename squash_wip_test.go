@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	config "github.com/remotemobprogramming/mob/v5/configuration"
 	"os"
 	"strings"
@@ -170,266 +169,171 @@ func TestSquashWipCommits_acceptanceWithDroppingStartCommit(t *testing.T) {
 	equals(t, commitsOnCurrentBranch(configuration), commitsOnRemoteBranch(configuration))
 }
 
-func TestCommitsOnCurrentBranch(t *testing.T) {
+func TestSquashWipCommits_wipCommitsFollowedByManualCommit(t *testing.T) {
 	_, configuration := setup(t)
-	createFileAndCommitIt(t, "file1.txt", "contentIrrelevant", "not on branch")
-	silentgit("push")
-	start(configuration)
-	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "on branch")
-	createFile(t, "file3.txt", "contentIrrelevant")
-	next(configuration)
+	wipCommit(t, configuration, "file1.txt")
+	wipCommit(t, configuration, "file2.txt")
+	manualCommit(t, configuration, "file3.txt", "manual commit")
 	start(configuration)
 
-	commits := commitsOnCurrentBranch(configuration)
-
 	equals(t, []string{
-		configuration.WipCommitMessage,
-		"on branch",
-	}, commits)
+		"manual commit: file1.txt file2.txt file3.txt",
+	}, squashedCommits(t, configuration))
 }
 
-func TestMarkSquashWip_singleManualCommit(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-	input := `pick c51a56d new file
-
-# Rebase ...`
-
-	result := markPostWipCommitsForSquashing(input, configuration)
-
-	equals(t, input, result)
-}
-
-func TestMarkSquashWip_manyManualCommits(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-	input := `pick c51a56d new file
-pick 63ef7a4 another commit
-
-# Rebase ...`
-
-	result := markPostWipCommitsForSquashing(input, configuration)
+func TestSquashWipCommits_wipThenManualCommitFollowedByManyWipCommits(t *testing.T) {
+	_, configuration := setup(t)
+	wipCommit(t, configuration, "file1.txt")
+	manualCommit(t, configuration, "file2.txt", "manual commit")
+	wipCommit(t, configuration, "file3.txt")
+	wipCommit(t, configuration, "file4.txt")
+	start(configuration)
 
-	equals(t, input, result)
+	equals(t, []string{
+		"manual commit: file1.txt file2.txt",
+		configuration.WipCommitMessage + ": file3.txt file4.txt",
+	}, squashedCommits(t, configuration))
 }
 
-func TestMarkSquashWip_wipCommitFollowedByManualCommit(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-	input := fmt.Sprintf(`pick 01a9a31 %s
-pick c51a56d manual commit
-
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick 01a9a31 %s
-squash c51a56d manual commit
-
-# Rebase ...`, configuration.WipCommitMessage)
-
-	result := markPostWipCommitsForSquashing(input, configuration)
+func TestSquashWipCommits_dropsStartCommit(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	git("commit", "--allow-empty", "--message", configuration.StartCommitMessage)
+	createFileAndCommitIt(t, "file1.txt", "contentIrrelevant", "manual commit")
+	createFile(t, "file2.txt", "contentIrrelevant")
+	next(configuration)
+	start(configuration)
 
-	equals(t, expected, result)
+	equals(t, []string{
+		"manual commit: file1.txt",
+		configuration.WipCommitMessage + ": file2.txt",
+	}, squashedCommits(t, configuration))
 }
 
-func TestMarkSquashWip_manyWipCommitsFollowedByManualCommit(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-	input := fmt.Sprintf(`pick 01a9a31 %[1]s
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-pick c51a56d manual commit
-
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick 01a9a31 %[1]s
-squash 01a9a32 %[1]s
-squash 01a9a33 %[1]s
-squash c51a56d manual commit
-
-# Rebase ...`, configuration.WipCommitMessage)
-
-	result := markPostWipCommitsForSquashing(input, configuration)
+func TestSquashWipCommits_keepsCommitNotStartingWithStartCommitMessage(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	git("commit", "--allow-empty", "--message", "not "+configuration.StartCommitMessage)
+	createFileAndCommitIt(t, "file1.txt", "contentIrrelevant", "manual commit")
 
-	equals(t, expected, result)
+	equals(t, []string{
+		"not " + configuration.StartCommitMessage + ":",
+		"manual commit: file1.txt",
+	}, squashedCommits(t, configuration))
 }
 
-func TestMarkSquashWip_manualCommitFollowedByWipCommit(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-	input := fmt.Sprintf(`pick c51a56d manual commit
-pick 01a9a31 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick c51a56d manual commit
-pick 01a9a31 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
+func TestCommitsOnCurrentBranch(t *testing.T) {
+	_, configuration := setup(t)
+	createFileAndCommitIt(t, "file1.txt", "contentIrrelevant", "not on branch")
+	silentgit("push")
+	start(configuration)
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "on branch")
+	createFile(t, "file3.txt", "contentIrrelevant")
+	next(configuration)
+	start(configuration)
 
-	result := markPostWipCommitsForSquashing(input, configuration)
+	commits := commitsOnCurrentBranch(configuration)
 
-	equals(t, expected, result)
+	equals(t, []string{
+		configuration.WipCommitMessage,
+		"on branch",
+	}, commits)
 }
 
-func TestMarkSquashWip_manualCommitFollowedByManyWipCommits(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-	input := fmt.Sprintf(`pick c51a56d manual commit
-pick 01a9a31 %[1]s
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick c51a56d manual commit
-pick 01a9a31 %[1]s
-fixup 01a9a32 %[1]s
-fixup 01a9a33 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
+func TestSquashWipCommits_withCustomCommentChar(t *testing.T) {
+	_, configuration := setup(t)
+	git("config", "core.commentChar", ";")
+	wipCommit(t, configuration, "file1.txt")
+	manualCommit(t, configuration, "file2.txt", "first manual commit")
+	start(configuration)
 
-	result := markPostWipCommitsForSquashing(input, configuration)
+	squashWip(configuration)
 
-	equals(t, expected, result)
+	equals(t, []string{
+		"first manual commit",
+	}, commitsOnCurrentBranch(configuration))
+	equals(t, "first manual commit", lastCommitMessage())
 }
 
-func TestMarkSquashWip_wipThenManualCommitFollowedByManyWipCommits(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-	input := fmt.Sprintf(`pick 01a9a31 %[1]s
-pick c51a56d manual commit
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick 01a9a31 %[1]s
-squash c51a56d manual commit
-pick 01a9a32 %[1]s
-fixup 01a9a33 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
+func TestSquashWipCommits_keepsMultilineMessageAndAuthorOfManualCommit(t *testing.T) {
+	_, configuration := setup(t)
+	wipCommit(t, configuration, "file1.txt")
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	git("add", "file2.txt")
+	git("commit", "--author", "alice <alice@example.com>", "-m", "first manual commit\n\nwith a body")
 
-	result := markPostWipCommitsForSquashing(input, configuration)
+	squashWip(configuration)
 
-	equals(t, expected, result)
+	// the author of the squashed wip commit becomes a co-author
+	equals(t, "first manual commit\n\nwith a body\n\nCo-authored-by: local <local@example.com>", lastCommitMessage())
+	equals(t, "alice <alice@example.com>", silentgit("log", "-1", "--pretty=format:%an <%ae>"))
+	assertFileExist(t, "file1.txt")
 }
 
-func TestMarkDropStartCommit_hasStartCISkipCommitLine(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-
-	input := fmt.Sprintf(`pick 01a9a31 %[2]s
-pick c51a56d manual commit
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage, configuration.StartCommitMessage)
-	expected := fmt.Sprintf(`drop 01a9a31 %[2]s
-pick c51a56d manual commit
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage, configuration.StartCommitMessage)
+func TestSquashWipCommits_keepsUnchangedCommits(t *testing.T) {
+	_, configuration := setup(t)
+	manualCommit(t, configuration, "file1.txt", "first manual commit")
+	start(configuration)
+	before := silentgit("rev-parse", "HEAD")
 
-	result := markStartCommitForDropping(input, configuration)
+	squashWip(configuration)
 
-	equals(t, expected, result)
+	equals(t, before, silentgit("rev-parse", "HEAD"))
 }
 
-// Check if the initial commit is not dropped when the commmit line does not contain `InitialCISkipCommitMessage`
-func TestMarkDropStartCommit_notHasStartCISkipCommitLine(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-
-	input := fmt.Sprintf(`pick 01a9a31 %[1]s
-pick c51a56d manual commit
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick 01a9a31 %[1]s
-pick c51a56d manual commit
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
+func TestSquashWipCommits_resetsAuthorEnv(t *testing.T) {
+	_, configuration := setup(t)
+	wipCommit(t, configuration, "file1.txt")
+	manualCommit(t, configuration, "file2.txt", "first manual commit")
+	start(configuration)
+	t.Setenv("GIT_AUTHOR_NAME", "irrelevant")
 
-	result := markStartCommitForDropping(input, configuration)
+	squashWip(configuration)
 
-	equals(t, expected, result)
+	equals(t, "irrelevant", os.Getenv("GIT_AUTHOR_NAME"))
+	equals(t, "", os.Getenv("GIT_AUTHOR_EMAIL"))
 }
 
-func TestCommentWipCommits_oneWipAndOneManualCommit(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-	input := fmt.Sprintf(`# This is a combination of 2 commits.
-# This is the 1st commit message:
-
-%s
-
-# This is the commit message #2:
-
-manual commit
-
-# Please enter ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`# This is a combination of 2 commits.
-# This is the 1st commit message:
-
-# %s
-
-# This is the commit message #2:
-
-manual commit
-
-# Please enter ...`, configuration.WipCommitMessage)
+func TestSquashWipCommits_keepsMergeOfBaseBranch(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.NextStay = true
+	start(configuration)
+	createFileAndCommitIt(t, "m1.txt", "contentIrrelevant", "manual M1")
+	createFile(t, "w1.txt", "contentIrrelevant")
+	next(configuration)
+	pushCommitToMasterFromLocalOther(t, "up.txt")
+	syncSession(configuration)
+	createFile(t, "w2.txt", "contentIrrelevant")
+	next(configuration)
 
-	result := commentWipCommits(input, configuration)
+	squashWip(configuration)
 
-	equals(t, expected, result)
+	manualCommit := silentgit("log", "--format=%H", "--grep=manual M1", "HEAD")
+	equals(t, "A\tm1.txt", silentgit("show", "--name-status", "--format=", manualCommit))
+	equals(t, false, doBranchesDiverge("origin/master", "HEAD"))
+	assertFileExist(t, "up.txt")
+	assertGitStatus(t, GitStatus{"w2.txt": "A"})
 }
 
-func TestSquashWipCommitGitEditor(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-	createTestbed(t, configuration)
-	input := createFile(t, "commits", fmt.Sprintf(
-		`# This is a combination of 2 commits.
-# This is the 1st commit message:
-
-%s
-
-# This is the commit message #2:
-
-new file
-
-# Please enter the commit message for your changes. Lines starting`, configuration.WipCommitMessage))
-	expected := fmt.Sprintf(
-		`# This is a combination of 2 commits.
-# This is the 1st commit message:
-
-# %s
-
-# This is the commit message #2:
-
-new file
-
-# Please enter the commit message for your changes. Lines starting`, configuration.WipCommitMessage)
+func TestDoneSquashWipAfterSync(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.NextStay = true
+	start(configuration)
+	createFileAndCommitIt(t, "m1.txt", "contentIrrelevant", "manual M1")
+	createFile(t, "w1.txt", "contentIrrelevant")
+	next(configuration)
+	pushCommitToMasterFromLocalOther(t, "up.txt")
+	syncSession(configuration)
+	configuration.DoneSquash = config.SquashWip
 
-	squashWipGitEditor(input, config.GetDefaultConfiguration())
+	done(configuration)
 
-	result, _ := os.ReadFile(input)
-	equals(t, expected, string(result))
-}
-
-func TestSquashWipCommitGitSequenceEditor(t *testing.T) {
-	configuration := config.GetDefaultConfiguration()
-	createTestbed(t, configuration)
-	input := createFile(t, "rebase", fmt.Sprintf(
-		`pick 01a9a31 %[1]s
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-pick c51a56d manual commit
-
-# Rebase ...
-`, configuration.WipCommitMessage))
-	expected := fmt.Sprintf(
-		`pick 01a9a31 %[1]s
-squash 01a9a32 %[1]s
-squash 01a9a33 %[1]s
-squash c51a56d manual commit
-
-# Rebase ...
-`, configuration.WipCommitMessage)
-
-	squashWipGitSequenceEditor(input, config.GetDefaultConfiguration())
-
-	result, _ := os.ReadFile(input)
-	equals(t, expected, string(result))
+	assertOnBranch(t, "master")
+	for _, hash := range strings.Split(silentgit("rev-list", "--no-merges", "origin/master..HEAD"), "\n") {
+		assertOutputNotContains(t, stringPointer(silentgit("show", "--name-status", "--format=", hash)), "up.txt")
+	}
+	assertFileExist(t, "up.txt")
 }
 
 func wipCommit(t *testing.T, configuration config.Configuration, filename string) {
@@ -444,6 +348,20 @@ func manualCommit(t *testing.T, configuration config.Configuration, filename str
 	next(configuration)
 }
 
+// the commits squashWipCommits builds on top of the remote base branch, oldest first, as "<subject>: <files>"
+func squashedCommits(t *testing.T, configuration config.Configuration) []string {
+	mergeBase := silentgit("merge-base", "HEAD", "origin/master")
+	newHead, err := squashWipCommits(mergeBase, configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits := []string{}
+	for _, hash := range strings.Split(silentgit("rev-list", "--reverse", mergeBase+".."+newHead), "\n") {
+		commits = append(commits, strings.Join(strings.Fields(silentgit("show", "--name-only", "--format=%s:", hash)), " "))
+	}
+	return commits
+}
+
 func commitsOnCurrentBranch(configuration config.Configuration) []string {
 	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 	commitsBaseWipBranch := currentBaseBranch.String() + ".." + currentWipBranch.String()
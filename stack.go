@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// a stacked session is a session whose base branch is the wip branch of another session, e.g. 'mob/mob/main-experiment'
+// on top of 'mob/main', so an experiment can branch off a running session and be merged back into it with 'mob done'
+const maxStackDepth = 10
+
+func isStackedSession(baseBranch Branch, configuration config.Configuration) bool {
+	return baseBranch.IsWipBranch(configuration)
+}
+
+func startStackedSession(configuration config.Configuration, uncommittedChanges bool) error {
+	parentBranch := gitCurrentBranch()
+	if !parentBranch.IsWipBranch(configuration) {
+		say.Error("cannot stack a session on '" + parentBranch.String() + "', because it is not a wip branch")
+		say.Fix("To start a session on '"+parentBranch.String()+"', use", configuration.Mob("start"))
+		return errors.New("cannot stack a session on a base branch")
+	}
+	if !parentBranch.hasRemoteBranch(configuration) || parentBranch.hasUnpushedCommits(configuration) {
+		say.Error("cannot stack a session; unpushed changes on wip branch '" + parentBranch.String() + "' must be pushed first")
+		say.Fix("To push them, use", configuration.Mob("next --stay"))
+		return errors.New("cannot stack a session; unpushed changes on wip branch must be pushed first")
+	}
+	stackedBranch := parentBranch.addWipPrefix(configuration).addWipQualifier(configuration)
+
+	if uncommittedChanges && configuration.HandleUncommittedChanges == config.DiscardChanges {
+		git("reset", "--hard")
+	}
+	includedChanges := false
+	if uncommittedChanges && configuration.HandleUncommittedChanges == config.IncludeChanges {
+		includedChanges = stashChangesToHandOver(configuration.StashName, nil)
+	}
+
+	if stackedBranch.hasRemoteBranch(configuration) {
+		say.Info("joining stacked session from " + stackedBranch.remote(configuration).String())
+		git("checkout", "-B", stackedBranch.Name, stackedBranch.remote(configuration).Name)
+		git("branch", "--set-upstream-to="+stackedBranch.remote(configuration).Name, stackedBranch.Name)
+	} else {
+		say.Info("starting stacked session from " + parentBranch.remote(configuration).String())
+		git("checkout", "-B", stackedBranch.Name, parentBranch.remote(configuration).Name)
+		gitWithoutEmptyStrings(append(gitPushArgs(configuration), gitHooksOption(configuration), "--set-upstream", configuration.RemoteName, stackedBranch.Name+":"+stackedBranch.Name, shareBaseBranchRefspec(stackedBranch, parentBranch))...)
+	}
+
+	if includedChanges {
+		git("stash", "pop", findStashByName(silentgit("stash", "list"), configuration.StashName))
+	}
+
+	rememberBaseBranch(stackedBranch, parentBranch)
+	say.Info("you are on wip branch '" + stackedBranch.String() + "' (base branch '" + parentBranch.String() + "')")
+	sayLastCommitsList(parentBranch, stackedBranch, configuration)
+	return nil
+}
+
+// merges the stacked session into the wip branch of its parent session and pushes it, the parent session continues
+func doneStacked(configuration config.Configuration, parentBranch Branch, wipBranch Branch) {
+	git("checkout", parentBranch.Name)
+	git("merge", parentBranch.remote(configuration).Name, "--ff-only")
+	mergeArgs := []string{"merge", "--squash", wipBranch.Name}
+	if configuration.DoneSquash != config.Squash {
+		mergeArgs = append([]string{"merge", "--ff", "--no-edit", wipBranch.Name}, signingOptions(configuration)...)
+	}
+	if err := gitIgnoreFailure(mergeArgs...); err != nil {
+		gitIgnoreFailure("reset", "--merge")
+		git("checkout", wipBranch.Name)
+		say.Error("Merging '" + wipBranch.String() + "' into '" + parentBranch.String() + "' stopped because of conflicts, you are back on '" + wipBranch.String() + "'.")
+		say.Fix("To solve the conflicts in the stacked session first, use", "git merge "+parentBranch.remote(configuration).Name)
+		return
+	}
+	if hasUncommittedChanges() {
+		makeWipCommit(configuration)
+	}
+	gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, parentBranch.Name)
+
+	git("branch", "-D", wipBranch.Name)
+	gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", wipBranch.Name, sharedBaseBranchRefToDelete(wipBranch))
+	forgetBaseBranch(wipBranch)
+	say.Info("merged stacked session '" + wipBranch.String() + "' into '" + parentBranch.String() + "' and pushed it")
+	say.Info("you are on wip branch '" + parentBranch.String() + "'")
+}
+
+// the branches from the base branch of the outermost session to the wip branch
+func sessionStack(wipBranch Branch, configuration config.Configuration) []Branch {
+	stack := []Branch{wipBranch}
+	localBranches := gitBranches()
+	for i := 0; i < maxStackDepth && stack[0].IsWipBranch(configuration); i++ {
+		baseBranch, _ := determineBranches(stack[0], localBranches, configuration)
+		if baseBranch == stack[0] {
+			break
+		}
+		stack = append([]Branch{baseBranch}, stack...)
+	}
+	return stack
+}
+
+func saySessionStack(wipBranch Branch, configuration config.Configuration) {
+	stack := sessionStack(wipBranch, configuration)
+	if len(stack) <= 2 {
+		return
+	}
+	say.Info("session stack:")
+	for i, branch := range stack {
+		say.WithPrefix(branch.String(), "  "+strings.Repeat("  ", i)+"- ")
+	}
+}
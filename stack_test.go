@@ -0,0 +1,140 @@
+package main
+
+import (
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestStartStackedSession(t *testing.T) {
+	output, configuration := setup(t)
+	startStackedSessionOnGreen(t, configuration)
+
+	assertOnBranch(t, "mob/mob/master-green-experiment")
+	assertMobSessionBranches(t, configuration, "mob/mob/master-green-experiment")
+	assertOutputContains(t, output, "starting stacked session from origin/mob/master-green")
+	assertOutputContains(t, output, "you are on wip branch 'mob/mob/master-green-experiment' (base branch 'mob/master-green')")
+	baseBranch, _ := determineBranches(gitCurrentBranch(), gitBranches(), config.GetDefaultConfiguration())
+	equals(t, "mob/master-green", baseBranch.Name)
+}
+
+func TestStartStackedSessionJoinsExistingStackedSession(t *testing.T) {
+	output, configuration := setup(t)
+	stackedConfiguration := startStackedSessionOnGreen(t, configuration)
+	git("checkout", "mob/master-green")
+
+	start(stackedConfiguration)
+
+	assertOnBranch(t, "mob/mob/master-green-experiment")
+	assertOutputContains(t, output, "joining stacked session from origin/mob/mob/master-green-experiment")
+}
+
+func TestStartStackedSessionOnBaseBranchFails(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.StartStack = true
+
+	err := start(configuration)
+
+	equals(t, true, err != nil)
+	assertOutputContains(t, output, "cannot stack a session on 'master', because it is not a wip branch")
+	assertOnBranch(t, "master")
+}
+
+func TestStartStackedSessionWithUnpushedCommitsFails(t *testing.T) {
+	output, configuration := setup(t)
+	startQualifiedSession(t, configuration, "green")
+	configuration.WipBranchQualifier = "green"
+	start(configuration)
+	createFileAndCommitIt(t, "unpushed.txt", "contentIrrelevant", "unpushed commit")
+	configuration.StartStack = true
+	configuration.WipBranchQualifier = "experiment"
+
+	err := start(configuration)
+
+	equals(t, true, err != nil)
+	assertOutputContains(t, output, "unpushed changes on wip branch 'mob/master-green' must be pushed first")
+	assertOnBranch(t, "mob/master-green")
+}
+
+func TestDoneStackedSessionMergesIntoParentSession(t *testing.T) {
+	output, configuration := setup(t)
+	stackedConfiguration := startStackedSessionOnGreen(t, configuration)
+	createFile(t, "experiment.txt", "contentIrrelevant")
+	stackedConfiguration.StartStack = false
+
+	done(stackedConfiguration)
+
+	assertOnBranch(t, "mob/master-green")
+	assertOutputContains(t, output, "merged stacked session 'mob/mob/master-green-experiment' into 'mob/master-green' and pushed it")
+	assertNoMobSessionBranches(t, configuration, "mob/mob/master-green-experiment")
+	assertGitStatus(t, GitStatus{})
+	equals(t, true, lastCommitIsWipCommit(configuration))
+	equals(t, silentgit("rev-parse", "HEAD"), silentgit("rev-parse", "origin/mob/master-green"))
+	assertFileExist(t, "experiment.txt")
+}
+
+func TestDoneStackedSessionWithoutSquashKeepsCommits(t *testing.T) {
+	_, configuration := setup(t)
+	stackedConfiguration := startStackedSessionOnGreen(t, configuration)
+	createFileAndCommitIt(t, "experiment.txt", "contentIrrelevant", "experiment")
+	stackedConfiguration.StartStack = false
+	stackedConfiguration.DoneSquash = config.NoSquash
+
+	done(stackedConfiguration)
+
+	assertOnBranch(t, "mob/master-green")
+	equals(t, "experiment", silentgit("log", "-1", "--format=%s", "origin/mob/master-green"))
+}
+
+func TestDoneStackedSessionWithConflictsReturnsToStackedSession(t *testing.T) {
+	output, configuration := setup(t)
+	stackedConfiguration := startStackedSessionOnGreen(t, configuration)
+	createFileAndCommitIt(t, "conflict.txt", "stacked", "stacked change")
+	git("checkout", "mob/master-green")
+	createFileAndCommitIt(t, "conflict.txt", "parent", "parent change")
+	git("push", "origin", "mob/master-green")
+	git("checkout", "mob/mob/master-green-experiment")
+	stackedConfiguration.StartStack = false
+
+	done(stackedConfiguration)
+
+	assertOnBranch(t, "mob/mob/master-green-experiment")
+	assertOutputContains(t, output, "Merging 'mob/mob/master-green-experiment' into 'mob/master-green' stopped because of conflicts")
+	assertOutputContains(t, output, "git merge origin/mob/master-green")
+	assertMobSessionBranches(t, configuration, "mob/mob/master-green-experiment")
+	assertGitStatus(t, GitStatus{})
+}
+
+func TestStatusShowsSessionStack(t *testing.T) {
+	output, configuration := setup(t)
+	startStackedSessionOnGreen(t, configuration)
+
+	status(enrichConfigurationWithBranchQualifier(configuration))
+
+	assertOutputContains(t, output, "session stack:")
+	assertOutputContains(t, output, "  - master\n")
+	assertOutputContains(t, output, "    - mob/master-green\n")
+	assertOutputContains(t, output, "      - mob/mob/master-green-experiment\n")
+}
+
+// starts the session 'mob/master-green' and the stacked session 'mob/mob/master-green-experiment' on top of it
+func startStackedSessionOnGreen(t *testing.T, configuration config.Configuration) config.Configuration {
+	startQualifiedSession(t, configuration, "green")
+	configuration.WipBranchQualifier = "green"
+	start(configuration)
+	configuration.StartStack = true
+	configuration.WipBranchQualifier = "experiment"
+	start(configuration)
+	return configuration
+}
+
+func TestGetSessionsGroupsStackedSessionByParentSession(t *testing.T) {
+	_, configuration := setup(t)
+	startStackedSessionOnGreen(t, configuration)
+
+	sessions := getSessions(configuration)
+
+	equals(t, 2, len(sessions))
+	equals(t, "mob/master-green", sessions[1].BaseBranch)
+	equals(t, "experiment", sessions[1].Qualifier)
+}
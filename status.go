@@ -9,6 +9,7 @@ func status(configuration config.Configuration) {
 	if isMobProgramming(configuration) {
 		currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 		say.Info("you are on wip branch " + currentWipBranch.String() + " (base branch " + currentBaseBranch.String() + ")")
+		saySessionStack(currentWipBranch, configuration)
 
 		sayLastCommitsList(currentBaseBranch, currentWipBranch, configuration)
 	} else {
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/exit"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// trailer of the wip commit that merges the remote base branch into the wip branch, e.g.
+// "Mob-Sync: merged origin/main 1a2b3c4"
+const syncTrailer = "Mob-Sync: "
+
+func syncSession(configuration config.Configuration) {
+	if !isMobProgramming(configuration) {
+		say.Fix("to start working together, use", configuration.Mob("start"))
+		return
+	}
+	if hasUncommittedChanges() {
+		say.Error("cannot sync; clean working tree required")
+		say.Fix("To hand over your changes first, use", configuration.Mob("next --stay"))
+		exit.Exit(1)
+		return
+	}
+
+	git("fetch", configuration.RemoteName, "--prune")
+	baseBranch, wipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	if wipBranch.hasRemoteBranch(configuration) {
+		gitIgnoreFailure("merge", wipBranch.remote(configuration).Name, "--ff-only")
+	}
+	if !syncWipBranch(configuration, baseBranch, wipBranch) {
+		exit.Exit(1)
+	}
+}
+
+// merges or rebases the remote base branch into the wip branch and pushes it, returns false if that failed because of
+// conflicts, in which case nothing was changed
+func syncWipBranch(configuration config.Configuration, baseBranch Branch, wipBranch Branch) bool {
+	upstream := baseBranch.remote(configuration).Name
+	if !doBranchesDiverge(upstream, wipBranch.Name) {
+		say.Info("'" + wipBranch.String() + "' is up to date with " + upstream)
+		return true
+	}
+	newCommits := silentgit("rev-list", "--count", wipBranch.Name+".."+upstream)
+
+	if configuration.SyncRebase {
+		if err := gitIgnoreFailure("rebase", upstream); err != nil {
+			gitIgnoreFailure("rebase", "--abort")
+			say.Error("Rebasing '" + wipBranch.String() + "' onto " + upstream + " stopped because of conflicts, nothing was changed.")
+			say.Fix("To merge instead, use", configuration.Mob("sync --merge"))
+			return false
+		}
+		gitWithoutEmptyStrings("push", "--force-with-lease", gitHooksOption(configuration), configuration.RemoteName, wipBranch.Name)
+		say.Info("rebased '" + wipBranch.String() + "' onto " + upstream + " (" + newCommits + " new commits) and pushed it")
+		say.Warning("The history of " + wipBranch.remote(configuration).Name + " was rewritten, the next 'mob start' tells everyone else about it.")
+		return true
+	}
+
+	message := configuration.WipCommitMessage + "\n\n" + syncTrailer + "merged " + upstream + " " + shortHash(silentgit("rev-parse", upstream))
+	mergeArgs := append([]string{"merge", "--no-ff", "--message", message, upstream}, signingOptions(configuration)...)
+	if err := gitIgnoreFailure(mergeArgs...); err != nil {
+		gitIgnoreFailure("merge", "--abort")
+		say.Error("Merging " + upstream + " into '" + wipBranch.String() + "' stopped because of conflicts, nothing was changed.")
+		say.Fix("To solve the conflicts yourself and hand over, use", "git merge "+upstream+" && "+configuration.Mob("next"))
+		return false
+	}
+	gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, wipBranch.Name)
+	say.Info("merged " + upstream + " (" + newCommits + " new commits) into '" + wipBranch.String() + "' and pushed it")
+	return true
+}
+
+// tells who synced the session since the local wip branch was last updated, or that its history was rewritten
+func warnAboutSyncedSession(configuration config.Configuration, wipBranch Branch) {
+	remoteWipBranch := wipBranch.remote(configuration).Name
+	if doBranchesDiverge(wipBranch.Name, remoteWipBranch) {
+		say.Warning("The history of " + remoteWipBranch + " was rewritten since you last joined, e.g. by 'mob sync --rebase'.")
+		say.Fix("Your local '"+wipBranch.String()+"' is replaced, to see its previous state, use", "git log "+shortHash(silentgit("rev-parse", wipBranch.Name)))
+		return
+	}
+	syncs, err := silentgitignorefailure("log", "--reverse", "--grep=^"+syncTrailer, "--format=%an%x09%B%x00", wipBranch.Name+".."+remoteWipBranch)
+	if err != nil {
+		return
+	}
+	for _, sync := range strings.Split(syncs, "\x00") {
+		author, message, found := strings.Cut(strings.TrimSpace(sync), "\t")
+		if !found {
+			continue
+		}
+		for _, line := range strings.Split(message, "\n") {
+			if strings.HasPrefix(line, syncTrailer) {
+				say.Warning(author + " " + strings.TrimPrefix(line, syncTrailer) + " into " + remoteWipBranch + " since you last joined.")
+			}
+		}
+	}
+}
+
+func shortHash(hash string) string {
+	return hash[:min(len(hash), 7)]
+}
+
+func sayBehindBaseBranch(configuration config.Configuration, baseBranch Branch, wipBranch Branch) {
+	behind, err := strconv.Atoi(silentgit("rev-list", "--count", wipBranch.Name+".."+baseBranch.remote(configuration).Name))
+	if err != nil || behind == 0 {
+		return
+	}
+	say.Info("'" + wipBranch.String() + "' is " + strconv.Itoa(behind) + " commits behind " + baseBranch.remote(configuration).Name)
+	say.Fix("To update it, use", configuration.Mob("sync"))
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/remotemobprogramming/mob/v5/workdir"
+)
+
+func TestSyncMergesBaseBranchIntoWipBranch(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	pushCommitToMasterFromLocalOther(t, "master.txt")
+
+	syncSession(configuration)
+
+	assertOnBranch(t, "mob-session")
+	assertOutputContains(t, output, "merged origin/master (1 new commits) into 'mob-session' and pushed it")
+	assertFileExist(t, "master.txt")
+	equals(t, silentgit("rev-parse", "HEAD"), silentgit("rev-parse", "origin/mob-session"))
+	equals(t, true, lastCommitIsWipCommit(configuration))
+	assertOutputContains(t, stringPointer(silentgit("log", "-1", "--format=%B")), syncTrailer+"merged origin/master ")
+}
+
+func TestSyncKeepsRotationCount(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.NextStay = true
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	pushCommitToMasterFromLocalOther(t, "master.txt")
+	syncSession(configuration)
+	equals(t, 1, countWipCommitsOfSession(configuration))
+
+	createFile(t, "file2.txt", "contentIrrelevant")
+	next(configuration)
+
+	equals(t, 2, parseWipCommitMetadata(lastCommitMessage()).Rotation)
+}
+
+func TestSyncWhenUpToDate(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+
+	syncSession(configuration)
+
+	assertOutputContains(t, output, "'mob-session' is up to date with origin/master")
+}
+
+func TestSyncRebasesWipBranch(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "wip.txt", "contentIrrelevant")
+	configuration.NextStay = true
+	next(configuration)
+	pushCommitToMasterFromLocalOther(t, "master.txt")
+	configuration.SyncRebase = true
+
+	syncSession(configuration)
+
+	assertOutputContains(t, output, "rebased 'mob-session' onto origin/master (1 new commits) and pushed it")
+	assertOutputContains(t, output, "The history of origin/mob-session was rewritten")
+	equals(t, "0", silentgit("rev-list", "--count", "--merges", "origin/master..origin/mob-session"))
+	equals(t, false, doBranchesDiverge("origin/master", "origin/mob-session"))
+	assertFileExist(t, "wip.txt")
+}
+
+func TestSyncWithConflictsChangesNothing(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "conflict.txt", "wip")
+	configuration.NextStay = true
+	next(configuration)
+	wipCommit := silentgit("rev-parse", "HEAD")
+	pushCommitToMasterFromLocalOther(t, "conflict.txt")
+	mockExit()
+	defer resetExit()
+
+	syncSession(configuration)
+
+	assertOutputContains(t, output, "Merging origin/master into 'mob-session' stopped because of conflicts, nothing was changed.")
+	equals(t, wipCommit, silentgit("rev-parse", "HEAD"))
+	assertGitStatus(t, GitStatus{})
+}
+
+func TestSyncRequiresCleanWorkingTree(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file.txt", "contentIrrelevant")
+	mockExit()
+	defer resetExit()
+
+	syncSession(configuration)
+
+	assertOutputContains(t, output, "cannot sync; clean working tree required")
+}
+
+func TestStartWithSyncMergesBaseBranch(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	git("checkout", "master")
+	pushCommitToMasterFromLocalOther(t, "master.txt")
+	configuration.StartSync = true
+
+	start(configuration)
+
+	assertOutputContains(t, output, "merged origin/master (1 new commits) into 'mob-session' and pushed it")
+	assertFileExist(t, "master.txt")
+}
+
+func TestStartSaysWipBranchIsBehindBaseBranch(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	git("checkout", "master")
+	pushCommitToMasterFromLocalOther(t, "master.txt")
+
+	start(configuration)
+
+	assertOutputContains(t, output, "'mob-session' is 1 commits behind origin/master")
+	assertOutputContains(t, output, "mob sync")
+}
+
+func TestStartWarnsAboutSyncSinceLastJoin(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	setWorkingDir(tempDir + "/local")
+	pushCommitToMasterFromLocalOther(t, "master.txt")
+	syncSession(configuration)
+	setWorkingDir(tempDir + "/alice")
+	git("checkout", "master")
+
+	start(configuration)
+
+	assertOutputContains(t, output, "local merged origin/master ")
+	assertOutputContains(t, output, " into origin/mob-session since you last joined.")
+}
+
+func TestStartWarnsAboutRewrittenHistory(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "wip.txt", "contentIrrelevant")
+	configuration.NextStay = true
+	next(configuration)
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	setWorkingDir(tempDir + "/local")
+	pushCommitToMasterFromLocalOther(t, "master.txt")
+	configuration.SyncRebase = true
+	syncSession(configuration)
+	setWorkingDir(tempDir + "/alice")
+	git("checkout", "master")
+
+	start(configuration)
+
+	assertOutputContains(t, output, "The history of origin/mob-session was rewritten since you last joined")
+	assertFileExist(t, "master.txt")
+}
+
+func pushCommitToMasterFromLocalOther(t *testing.T, filename string) {
+	currentDir := workdir.Path
+	setWorkingDir(tempDir + "/localother")
+	git("pull", "--ff-only")
+	createFileAndCommitIt(t, filename, "from localother", "commit on master")
+	git("push", "origin", "master")
+	setWorkingDir(currentDir)
+	git("fetch", "origin")
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
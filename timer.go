@@ -14,7 +14,11 @@ func StartTimer(timerInMinutes string, configuration config.Configuration) {
 
 func startTimer(timerInMinutes string, configuration config.Configuration) error {
 	configuration = enrichConfigurationWithBranchQualifier(configuration)
-	return timer.RunTimer(timerInMinutes, configuration)
+	if err := timer.RunTimer(timerInMinutes, configuration); err != nil {
+		return err
+	}
+	rememberTimer(timerInMinutes)
+	return nil
 }
 
 func StartBreakTimer(timerInMinutes string, configuration config.Configuration) {
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// trailers of wip commits, 'lastFile:' is kept as it is for older versions of mob
+const (
+	lastFileTrailer     = "lastFile:"
+	lastFileLineTrailer = "Mob-Last-File-Line: "
+	recentFileTrailer   = "Mob-Recent-File: "
+	driverTrailer       = "Mob-Driver: "
+	rotationTrailer     = "Mob-Rotation: "
+	timerTrailer        = "Mob-Timer: "
+	goalTrailer         = "Mob-Goal: "
+)
+
+// the number of most recently modified files recorded in a wip commit, including the last file
+const maxRecentFiles = 10
+
+// the length of the last timer started in this repository, in minutes
+const lastTimerFile = "mob-timer"
+
+// the goal of the timer room, as last shown, set or deleted with 'mob goal', so that 'mob next' works offline
+const lastGoalFile = "mob-goal"
+
+// WipCommitMetadata is written as trailers into every wip commit, so that 'mob start' can restore the context of the
+// previous driver and other tools can analyse the session
+type WipCommitMetadata struct {
+	LastFile     string   // relative to the root of the repository, quoted by git if it contains special characters
+	LastFileLine int      // first changed line in LastFile, 0 if unknown
+	RecentFiles  []string // the files modified before LastFile, most recent first
+	Driver       string   // "Full Name <email>"
+	Rotation     int      // 1 for the first wip commit of the session
+	Timer        string   // length of the timer in minutes
+	Goal         string
+}
+
+func createWipCommitMetadata(configuration config.Configuration) WipCommitMetadata {
+	metadata := WipCommitMetadata{
+		Driver:   currentAuthor(),
+		Rotation: countWipCommitsOfSession(configuration) + 1,
+		Timer:    lastTimer(configuration),
+		Goal:     lastGoal(),
+	}
+	files := getPathsOfModifiedFilesByRecency()
+	if len(files) > 0 {
+		metadata.LastFile = files[0]
+		metadata.LastFileLine = firstChangedLine(files[0])
+		metadata.RecentFiles = files[1:min(len(files), maxRecentFiles)]
+	}
+	return metadata
+}
+
+func (m WipCommitMetadata) trailers() []string {
+	var trailers []string
+	if m.LastFile != "" {
+		trailers = append(trailers, lastFileTrailer+m.LastFile)
+	}
+	if m.LastFileLine > 0 {
+		trailers = append(trailers, lastFileLineTrailer+strconv.Itoa(m.LastFileLine))
+	}
+	for _, file := range m.RecentFiles {
+		trailers = append(trailers, recentFileTrailer+file)
+	}
+	if m.Driver != "" {
+		trailers = append(trailers, driverTrailer+m.Driver)
+	}
+	if m.Rotation > 0 {
+		trailers = append(trailers, rotationTrailer+strconv.Itoa(m.Rotation))
+	}
+	if m.Timer != "" {
+		trailers = append(trailers, timerTrailer+m.Timer)
+	}
+	if m.Goal != "" {
+		trailers = append(trailers, goalTrailer+strings.Join(strings.Fields(m.Goal), " "))
+	}
+	return trailers
+}
+
+// reads the metadata from the trailers of a wip commit, missing trailers stay empty
+func parseWipCommitMetadata(message string) WipCommitMetadata {
+	metadata := WipCommitMetadata{}
+	for _, line := range strings.Split(message, "\n") {
+		switch {
+		case strings.HasPrefix(line, lastFileTrailer):
+			metadata.LastFile = strings.TrimPrefix(line, lastFileTrailer)
+		case strings.HasPrefix(line, lastFileLineTrailer):
+			metadata.LastFileLine, _ = strconv.Atoi(strings.TrimPrefix(line, lastFileLineTrailer))
+		case strings.HasPrefix(line, recentFileTrailer):
+			metadata.RecentFiles = append(metadata.RecentFiles, strings.TrimPrefix(line, recentFileTrailer))
+		case strings.HasPrefix(line, driverTrailer):
+			metadata.Driver = strings.TrimPrefix(line, driverTrailer)
+		case strings.HasPrefix(line, rotationTrailer):
+			metadata.Rotation, _ = strconv.Atoi(strings.TrimPrefix(line, rotationTrailer))
+		case strings.HasPrefix(line, timerTrailer):
+			metadata.Timer = strings.TrimPrefix(line, timerTrailer)
+		case strings.HasPrefix(line, goalTrailer):
+			metadata.Goal = strings.TrimPrefix(line, goalTrailer)
+		}
+	}
+	return metadata
+}
+
+// the unquoted path of the last file
+func (m WipCommitMetadata) lastFilePath() string {
+	return unquoteFilePath(m.LastFile)
+}
+
+// git quotes paths with special characters, e.g. "file with spaces.txt"
+func unquoteFilePath(file string) string {
+	if strings.HasPrefix(file, "\"") {
+		unquoted, err := strconv.Unquote(file)
+		if err != nil {
+			say.Debug("Could not unquote file path " + file)
+			return ""
+		}
+		return unquoted
+	}
+	return file
+}
+
+func countWipCommitsOfSession(configuration config.Configuration) int {
+	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	base := currentBaseBranch.Name
+	if currentBaseBranch.hasRemoteBranch(configuration) {
+		base = currentBaseBranch.remote(configuration).Name
+	}
+	return countWipCommits(configuration, base+".."+currentWipBranch.Name)
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -[0-9,]+ \+([0-9]+)`)
+
+// the first line the staged changes of the file touch, so that the next driver can continue right there
+func firstChangedLine(file string) int {
+	diff, err := silentgitignorefailure("-C", gitRootDir(), "diff", "--cached", "--unified=0", "--no-color", "--", unquoteFilePath(file))
+	if err != nil {
+		return 0
+	}
+	return firstLineOfDiff(diff)
+}
+
+func firstLineOfDiff(diff string) int {
+	for _, line := range strings.Split(diff, "\n") {
+		if matches := hunkHeader.FindStringSubmatch(line); matches != nil {
+			lineNumber, _ := strconv.Atoi(matches[1])
+			return max(lineNumber, 1)
+		}
+	}
+	return 0
+}
+
+func rememberTimer(timerInMinutes string) {
+	if !isGit() {
+		return
+	}
+	if err := os.WriteFile(gitDir()+"/"+lastTimerFile, []byte(timerInMinutes), 0644); err != nil {
+		say.Debug("Could not remember the timer: " + err.Error())
+	}
+}
+
+func rememberGoal(goal string) {
+	if !isGit() {
+		return
+	}
+	if err := os.WriteFile(gitDir()+"/"+lastGoalFile, []byte(goal), 0644); err != nil {
+		say.Debug("Could not remember the goal: " + err.Error())
+	}
+}
+
+func lastGoal() string {
+	content, err := os.ReadFile(gitDir() + "/" + lastGoalFile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+func lastTimer(configuration config.Configuration) string {
+	if content, err := os.ReadFile(gitDir() + "/" + lastTimerFile); err == nil {
+		return strings.TrimSpace(string(content))
+	}
+	return configuration.Timer
+}
+
+// tells the next driver where the previous rotation ended
+func sayLastRotation(metadata WipCommitMetadata) {
+	if metadata.Driver == "" {
+		return
+	}
+	summary := "rotation " + strconv.Itoa(metadata.Rotation) + " was driven by " + metadata.Driver
+	if metadata.Timer != "" {
+		summary += " with a " + metadata.Timer + " minute timer"
+	}
+	say.Info(summary)
+	if metadata.Goal != "" {
+		say.Info("goal: " + metadata.Goal)
+	}
+	if metadata.LastFile != "" {
+		location := metadata.lastFilePath()
+		if metadata.LastFileLine > 0 {
+			location += ":" + strconv.Itoa(metadata.LastFileLine)
+		}
+		say.Info("last change: " + location)
+	}
+}
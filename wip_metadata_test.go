@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNextWritesWipCommitMetadata(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.NextStay = true
+
+	start(configuration)
+	createFile(t, "file1.txt", "one\ntwo\nthree\n")
+	next(configuration)
+
+	start(configuration)
+	createFile(t, "file1.txt", "one\ntwo\nthree and a half\n")
+	rememberTimer("15")
+	rememberGoal("make it green")
+	next(configuration)
+
+	equals(t, WipCommitMetadata{
+		LastFile:     "file1.txt",
+		LastFileLine: 3,
+		Driver:       "local <local@example.com>",
+		Rotation:     2,
+		Timer:        "15",
+		Goal:         "make it green",
+	}, parseWipCommitMetadata(silentgit("log", "-1", "--pretty=format:%B", "origin/mob-session")))
+}
+
+func TestStartSaysLastRotation(t *testing.T) {
+	output, configuration := setup(t)
+
+	start(configuration)
+	createFile(t, "file1.txt", "one\ntwo\n")
+	rememberTimer("10")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+
+	assertOutputContains(t, output, "rotation 1 was driven by local <local@example.com> with a 10 minute timer")
+	assertOutputContains(t, output, "last change: file1.txt:1")
+}
+
+func TestParseWipCommitMetadata(t *testing.T) {
+	metadata := parseWipCommitMetadata("mob next [ci-skip] [ci skip] [skip ci]\n\nlastFile:\"dir/file with spaces.txt\"\nMob-Last-File-Line: 42\nMob-Driver: Alice <alice@example.com>\nMob-Rotation: 7\nMob-Timer: 10\nMob-Goal: make it green\nCo-authored-by: Bob <bob@example.com>")
+
+	equals(t, WipCommitMetadata{
+		LastFile:     "\"dir/file with spaces.txt\"",
+		LastFileLine: 42,
+		Driver:       "Alice <alice@example.com>",
+		Rotation:     7,
+		Timer:        "10",
+		Goal:         "make it green",
+	}, metadata)
+	equals(t, "dir/file with spaces.txt", metadata.lastFilePath())
+	equals(t, []string{"lastFile:\"dir/file with spaces.txt\"", "Mob-Last-File-Line: 42", "Mob-Driver: Alice <alice@example.com>", "Mob-Rotation: 7", "Mob-Timer: 10", "Mob-Goal: make it green"}, metadata.trailers())
+}
+
+func TestParseWipCommitMetadataOfOlderVersions(t *testing.T) {
+	equals(t, WipCommitMetadata{LastFile: "file1.txt"}, parseWipCommitMetadata("mob next [ci-skip] [ci skip] [skip ci]\n\nlastFile:file1.txt"))
+}